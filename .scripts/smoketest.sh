@@ -24,7 +24,7 @@ show_help() {
 
 clean_files() {
     echo "Cleaning up generated files..."
-    rm -f testdata/*.pdf testdata/*.html testdata/*.en.md
+    rm -f testdata/*.pdf testdata/*.html testdata/*.en.md testdata/*.xlf testdata/*.tmx testdata/*.po
 }
 
 PASS=0
@@ -94,11 +94,17 @@ echo ""
 echo "--- Should succeed (quick) ---"
 run_expect_ok "--help" --help
 run_expect_ok "--list-languages" --list-languages
+run_expect_ok "--list-engines" --list-engines
 run_expect_ok "with --translation file (no network)" \
     testdata/sample.fr.md --translation testdata/sample.es.md
 run_expect_ok "small fr->es" testdata/sample.fr.md --translation testdata/sample.es.md --font-size small --output testdata/sample.fr.es.s.pdf
 run_expect_ok "medium fr->es -a" testdata/sample.fr.md --translation testdata/sample.es.md --font-size medium --output testdata/sample.fr.es.m.pdf --attribution
 run_expect_ok "large fr->es" testdata/sample.fr.md --translation testdata/sample.es.md --font-size large --output testdata/sample.fr.es.l.pdf
+run_expect_ok "pseudo engine (no network)" testdata/sample.fr.md --engine pseudo --no-cache --output testdata/sample.fr.es.pseudo.pdf
+run_expect_ok "pseudo engine --granularity sentence" testdata/sample.fr.md --engine pseudo --no-cache --granularity sentence --output testdata/sample.fr.es.sentence.pdf
+run_expect_ok "export-xliff" export-xliff testdata/sample.fr.md
+run_expect_ok "export-po" export-po testdata/sample.fr.md
+run_expect_ok "--save-tmx from --translation" testdata/sample.fr.md --translation testdata/sample.es.md --save-tmx
 
 echo ""
 echo "--- Should fail ---"
@@ -110,6 +116,11 @@ run_expect_fail "non-.md file" README.txt
 run_expect_fail "nonexistent translation" testdata/sample.fr.md --translation missing.md
 run_expect_fail "output not .pdf" testdata/sample.fr.md -o out.txt
 run_expect_fail "invalid --font-size" testdata/sample.fr.md --font-size huge
+run_expect_fail "unknown --engine" testdata/sample.fr.md --engine nope
+run_expect_fail "--translation-po not .po" testdata/sample.fr.md --translation-po testdata/sample.es.md
+run_expect_fail "--tm not .tmx" testdata/sample.fr.md --tm testdata/sample.es.md
+run_expect_fail "invalid --granularity" testdata/sample.fr.md --granularity word
+run_expect_fail "--translation-xliff not .xlf" testdata/sample.fr.md --translation-xliff testdata/sample.es.md
 
 if $FULL; then
     echo ""
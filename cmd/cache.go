@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"bilingual_pdf/internal/cache"
+
+	"github.com/spf13/cobra"
+)
+
+var pruneOlderThan string
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clean the translation cache",
+	Long: `Manages the on-disk translation memory used to avoid retranslating
+unchanged blocks. The cache lives in .bilingual/cache when a .bilingual
+directory exists in the working directory, otherwise in the user cache
+directory.`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the number of cached translations per engine and language pair",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mem, err := openCache()
+		if err != nil {
+			return err
+		}
+		stats, err := mem.Stats()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Cache directory: %s\n", mem.Dir)
+		if len(stats) == 0 {
+			fmt.Println("Cache is empty.")
+			return nil
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "Engine\tPair\tEntries\tSize\tLast used")
+		_, _ = fmt.Fprintln(tw, "------\t----\t-------\t----\t---------")
+		for _, s := range stats {
+			_, _ = fmt.Fprintf(tw, "%s\t%s→%s\t%d\t%d KB\t%s\n",
+				s.Engine, s.Source, s.Target, s.Entries, (s.Bytes+1023)/1024, s.Newest.Format("2006-01-02"))
+		}
+		return tw.Flush()
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove cached translations not used recently",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		age, err := parseAge(pruneOlderThan)
+		if err != nil {
+			return fmt.Errorf("invalid --older-than %q: %w", pruneOlderThan, err)
+		}
+		mem, err := openCache()
+		if err != nil {
+			return err
+		}
+		removed, err := mem.Prune(time.Now().Add(-age))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %d cached translations.\n", removed)
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the whole translation cache",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mem, err := openCache()
+		if err != nil {
+			return err
+		}
+		if err := mem.Clear(); err != nil {
+			return err
+		}
+		fmt.Printf("Cleared %s\n", mem.Dir)
+		return nil
+	},
+}
+
+func init() {
+	cachePruneCmd.Flags().StringVar(&pruneOlderThan, "older-than", "90d", "remove entries not used for this long (e.g. 30d, 12h)")
+	cacheCmd.AddCommand(cacheStatsCmd, cachePruneCmd, cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func openCache() (*cache.Memory, error) {
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return cache.New(dir), nil
+}
+
+// parseAge parses a duration, additionally accepting a number of days such as "30d".
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("expected a number of days")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"bilingual_pdf/internal/languages"
+	"bilingual_pdf/internal/parser"
+	"bilingual_pdf/internal/translator"
+)
+
+const (
+	// detectSampleSize bounds the text sent for detection of the document language.
+	detectSampleSize = 2000
+	// lowConfidence is the detection confidence below which the user is warned.
+	lowConfidence = 0.5
+	// mixedMinWords is the length from which a block is checked for another language.
+	mixedMinWords = 8
+)
+
+// detectSource replaces --source auto with the language detected in the
+// document, using the detection of engine when it offers one and the
+// offline detector otherwise, and warns about blocks that seem to be in
+// another language. An empty engine, for commands that work offline, means
+// the offline detector.
+func detectSource(ctx context.Context, blocks []parser.Block, engine string) error {
+	sample := detectionSample(blocks)
+
+	var d languages.Detection
+	method := "offline detector"
+	if engine != "" {
+		if tr, err := translator.New(engine, engineConfig()); err == nil {
+			if det, ok := tr.(translator.Detector); ok {
+				code, confidence, err := det.Detect(ctx, sample)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %s language detection failed, using the offline detector: %v\n", engine, err)
+				} else {
+					d = languages.Detection{Code: code, Confidence: confidence}
+					method = engine
+				}
+			}
+		}
+	}
+	if d.Code == "" {
+		d = languages.Detect(sample)
+		method = "offline detector"
+	}
+
+	if d.Code == "" {
+		return fmt.Errorf("could not detect the source language: use --source <code>")
+	}
+	if err := languages.Validate(d.Code); err != nil {
+		return fmt.Errorf("detected source language: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Detected source language: %s (%s), confidence %.0f%% (%s)\n",
+		languages.Name(d.Code), d.Code, d.Confidence*100, method)
+	if d.Confidence < lowConfidence {
+		fmt.Fprintln(os.Stderr, "Warning: low detection confidence; pass --source if the detected language is wrong")
+	}
+
+	sourceLang = d.Code
+	if sourceLang == targetLang {
+		fmt.Fprintf(os.Stderr, "Warning: source and target languages are the same (%s)\n", sourceLang)
+	}
+	warnMixedLanguages(blocks, sourceLang)
+	return nil
+}
+
+// detectionSample joins the translatable text of the blocks, up to
+// detectSampleSize bytes.
+func detectionSample(blocks []parser.Block) string {
+	var buf strings.Builder
+	for _, b := range blocks {
+		if !isDetectable(b) {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(b.Text)
+		if buf.Len() >= detectSampleSize {
+			break
+		}
+	}
+	return buf.String()
+}
+
+// warnMixedLanguages warns about every block long enough to identify that
+// the offline detector confidently places in a language other than source.
+func warnMixedLanguages(blocks []parser.Block, source string) {
+	for i, b := range blocks {
+		if !isDetectable(b) || len(strings.Fields(b.Text)) < mixedMinWords {
+			continue
+		}
+		d := languages.Detect(b.Text)
+		if d.Code != "" && d.Code != source && d.Confidence >= lowConfidence {
+			fmt.Fprintf(os.Stderr, "Warning: block %d seems to be in %s (%s), not %s\n",
+				i, languages.Name(d.Code), d.Code, languages.Name(source))
+		}
+	}
+}
+
+// isDetectable reports whether a block holds natural-language text.
+func isDetectable(b parser.Block) bool {
+	return b.Kind != parser.BlockCodeBlock && b.Kind != parser.BlockThematicBreak && b.Kind != parser.BlockHTML
+}
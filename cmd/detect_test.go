@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"bilingual_pdf/internal/parser"
+	"bilingual_pdf/internal/translator"
+)
+
+func TestDetectSource_Offline(t *testing.T) {
+	oldSource, oldTarget, oldEngine := sourceLang, targetLang, engineName
+	defer func() { sourceLang, targetLang, engineName = oldSource, oldTarget, oldEngine }()
+	sourceLang, targetLang, engineName = "auto", "es", "google"
+
+	blocks := []parser.Block{
+		{Kind: parser.BlockHeading, Text: "Le jardin"},
+		{Kind: parser.BlockCodeBlock, Text: "for the code in this block, the language is not detected"},
+		{Kind: parser.BlockParagraph, Text: "Le chat est sur la table et il regarde les oiseaux dans le jardin."},
+	}
+	if err := detectSource(context.Background(), blocks, engineName); err != nil {
+		t.Fatalf("detectSource failed: %v", err)
+	}
+	if sourceLang != "fr" {
+		t.Errorf("expected fr, got %s", sourceLang)
+	}
+}
+
+func TestDetectSource_Undetermined(t *testing.T) {
+	oldSource, oldEngine := sourceLang, engineName
+	defer func() { sourceLang, engineName = oldSource, oldEngine }()
+	sourceLang, engineName = "auto", "google"
+
+	err := detectSource(context.Background(), []parser.Block{{Kind: parser.BlockParagraph, Text: "42"}}, engineName)
+	if err == nil || !strings.Contains(err.Error(), "--source") {
+		t.Errorf("expected an error suggesting --source, got %v", err)
+	}
+}
+
+// germanDetector is an engine that detects German in any text, counting
+// its calls.
+type germanDetector struct{ calls *int }
+
+func (germanDetector) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	return blocks, nil
+}
+
+func (d germanDetector) Detect(ctx context.Context, text string) (string, float64, error) {
+	*d.calls++
+	return "de", 1, nil
+}
+
+func TestDetectSource_Engine(t *testing.T) {
+	oldSource, oldTarget := sourceLang, targetLang
+	defer func() { sourceLang, targetLang = oldSource, oldTarget }()
+	calls := 0
+	translator.Register(translator.Engine{
+		Name: "test-detector",
+		New:  func(translator.Config) (translator.Translator, error) { return germanDetector{&calls}, nil },
+	})
+	blocks := []parser.Block{{Kind: parser.BlockParagraph, Text: "Le chat est sur la table et il regarde les oiseaux dans le jardin."}}
+
+	sourceLang, targetLang = "auto", "es"
+	if err := detectSource(context.Background(), blocks, "test-detector"); err != nil || sourceLang != "de" {
+		t.Errorf("the engine should detect the language, got %s, %v", sourceLang, err)
+	}
+
+	// Without an engine, as for the exports, the offline detector is used.
+	sourceLang = "auto"
+	if err := detectSource(context.Background(), blocks, ""); err != nil || sourceLang != "fr" {
+		t.Errorf("the offline detector should detect the language, got %s, %v", sourceLang, err)
+	}
+	if calls != 1 {
+		t.Errorf("expected one call to the engine, got %d", calls)
+	}
+}
+
+func TestDetectionSample_SkipsCode(t *testing.T) {
+	blocks := []parser.Block{
+		{Kind: parser.BlockParagraph, Text: "Bonjour"},
+		{Kind: parser.BlockCodeBlock, Text: "func main() {}"},
+		{Kind: parser.BlockHTML, Text: "<div></div>"},
+		{Kind: parser.BlockList, Text: "un\ndeux"},
+	}
+	if got := detectionSample(blocks); got != "Bonjour\nun\ndeux" {
+		t.Errorf("unexpected sample %q", got)
+	}
+}
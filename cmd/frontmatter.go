@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"bilingual_pdf/internal/converter"
+	"bilingual_pdf/internal/parser"
+
+	"github.com/spf13/cobra"
+)
+
+// frontMatter is the YAML front matter of the input file, nil if it has
+// none.
+var frontMatter *parser.Meta
+
+// loadFrontMatter reads the front matter of the input file. Its settings
+// become the values of the flags of cmd not given on the command line; a
+// glossary is found relative to the input file.
+func loadFrontMatter(cmd *cobra.Command, inputFile string) error {
+	source, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("reading input file: %w", err)
+	}
+	meta, _, err := parser.FrontMatter(source)
+	if err != nil {
+		return fmt.Errorf("%s: %w", inputFile, err)
+	}
+	frontMatter = meta
+	if meta == nil {
+		return nil
+	}
+
+	glossary := meta.Glossary
+	if glossary != "" && !filepath.IsAbs(glossary) {
+		glossary = filepath.Join(filepath.Dir(inputFile), glossary)
+	}
+	for _, d := range []struct {
+		flag  string
+		value string
+		dest  *string
+	}{
+		{"source", meta.Source, &sourceLang},
+		{"target", meta.Target, &targetLang},
+		{"font-size", meta.FontSize, &fontSize},
+		{"engine", meta.Engine, &engineName},
+		{"glossary", glossary, &glossaryFile},
+		{"page-size", meta.PageSize, &pageSize},
+	} {
+		if d.value != "" && cmd.Flags().Lookup(d.flag) != nil && !cmd.Flags().Changed(d.flag) {
+			*d.dest = d.value
+		}
+	}
+	return nil
+}
+
+// documentTitle returns the title of the document: the title of the front
+// matter, or the language pair.
+func documentTitle(source, target string) string {
+	if frontMatter != nil && frontMatter.Title != "" {
+		return frontMatter.Title
+	}
+	return fmt.Sprintf("Bilingual: %s → %s", source, target)
+}
+
+// pdfInfo returns the document information of the PDF from the front
+// matter. A date that is not in ISO 8601 form is left out.
+func pdfInfo(title string) converter.Info {
+	info := converter.Info{Title: title}
+	if frontMatter == nil {
+		return info
+	}
+	info.Author = frontMatter.Author
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, frontMatter.Date); err == nil {
+			info.Date = t
+			break
+		}
+	}
+	return info
+}
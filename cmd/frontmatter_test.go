@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestLoadFrontMatter(t *testing.T) {
+	usePseudoEngine(t)
+	oldGlossary, oldPageSize, oldFrontMatter := glossaryFile, pageSize, frontMatter
+	t.Cleanup(func() { glossaryFile, pageSize, frontMatter = oldGlossary, oldPageSize, oldFrontMatter })
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "doc.md")
+	src := "---\ntitle: Le fromage\nauthor: Marie\ndate: 2024-05-01\nsource: de\ntarget: it\nglossary: terms.csv\npage-size: letter\n---\n# Titre\n"
+	if err := os.WriteFile(input, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVarP(&sourceLang, "source", "s", "fr", "")
+	cmd.Flags().StringVarP(&targetLang, "target", "t", "es", "")
+	cmd.Flags().StringVar(&glossaryFile, "glossary", "", "")
+	cmd.Flags().StringVar(&pageSize, "page-size", "a4", "")
+	if err := cmd.Flags().Parse([]string{"--target", "pt"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadFrontMatter(cmd, input); err != nil {
+		t.Fatalf("loadFrontMatter failed: %v", err)
+	}
+
+	if sourceLang != "de" {
+		t.Errorf("source = %q, want the front matter's de", sourceLang)
+	}
+	if targetLang != "pt" {
+		t.Errorf("target = %q, want the flag's pt", targetLang)
+	}
+	if glossaryFile != filepath.Join(dir, "terms.csv") {
+		t.Errorf("glossary = %q, want it relative to the document", glossaryFile)
+	}
+	if pageSize != "letter" {
+		t.Errorf("page size = %q, want letter", pageSize)
+	}
+	if info := pdfInfo("Le fromage"); info.Author != "Marie" || info.Date.Format("2006-01-02") != "2024-05-01" {
+		t.Errorf("pdfInfo = %+v", info)
+	}
+
+	// The metadata reaches the HTML; the front matter is not a block.
+	glossaryFile, sourceLang, targetLang = "", "fr", "es"
+	html, err := buildHTML(context.Background(), input)
+	if err != nil {
+		t.Fatalf("buildHTML failed: %v", err)
+	}
+	for _, want := range []string{"<title>Le fromage</title>", `<meta name="author" content="Marie">`, "size: letter;"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("rendered HTML should contain %q", want)
+		}
+	}
+	if strings.Contains(html, "<hr>") || strings.Contains(html, "author: Marie") {
+		t.Errorf("the front matter should not be rendered")
+	}
+}
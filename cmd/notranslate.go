@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"bilingual_pdf/internal/parser"
+	"bilingual_pdf/internal/placeholder"
+	"bilingual_pdf/internal/translator"
+)
+
+// phrasePrefix is the placeholder prefix of the marked phrases of a block.
+const phrasePrefix = 'N'
+
+// phraseTranslator hands the texts to translate to Inner with their marked
+// phrases replaced by placeholders, and puts the phrases back into the
+// translations. It wraps the engine inside the translation memory, which
+// holds the source text as written, markers included.
+type phraseTranslator struct {
+	Inner translator.Translator
+}
+
+func (p phraseTranslator) Translate(ctx context.Context, texts []string, source, target string) ([]string, error) {
+	protected := make([]string, len(texts))
+	sets := make([]*placeholder.Set, len(texts))
+	for i, text := range texts {
+		sets[i] = placeholder.NewSet(phrasePrefix)
+		protected[i] = protectPhrases(text, sets[i])
+	}
+	translated, err := p.Inner.Translate(ctx, protected, source, target)
+	restorePhrases(translated, sets)
+	return translated, err
+}
+
+// protectPhrases replaces the phrases marked [text]{.notranslate} or
+// [text]{translation="..."} in a text to translate with placeholders that
+// stand for their translation: the text itself or the fixed translation.
+func protectPhrases(text string, set *placeholder.Set) string {
+	return parser.ReplacePhrases(text, func(p parser.Phrase) string {
+		if p.Translation != "" {
+			return set.Add(p.Translation)
+		}
+		return set.Add(p.Text)
+	})
+}
+
+// restorePhrases puts the marked phrases back into the translated texts,
+// warning about the phrases an engine dropped.
+func restorePhrases(translatedTexts []string, sets []*placeholder.Set) {
+	for i, text := range translatedTexts {
+		if i >= len(sets) || sets[i].Len() == 0 || text == "" {
+			continue
+		}
+		var missing []string
+		translatedTexts[i], missing = sets[i].Restore(text)
+		if len(missing) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: block %d: the translation lost %d marked phrase(s): %s\n",
+				i, len(missing), strings.Join(quoteAll(missing), ", "))
+		}
+	}
+}
+
+// markedTranslation returns the translation of a block marked in the
+// source: the block itself for <!-- notranslate -->, or its fixed
+// translation for <!-- translate: ... -->. It reports false for other
+// blocks.
+func markedTranslation(b parser.Block) (parser.Block, bool) {
+	switch {
+	case b.NoTranslate:
+		return b, true
+	case b.Translation == "":
+		return parser.Block{}, false
+	case b.Kind == parser.BlockHTML:
+		return parser.Block{Kind: parser.BlockHTML, Text: b.Translation, Raw: b.Translation, HTML: b.Translation}, true
+	}
+	return parseTranslatedBlock(b.Kind, reconstructMarkdown(b, b.Translation)), true
+}
+
+// applyMarkers replaces the translations of marked blocks in the rows of a
+// translation read from a file.
+func applyMarkers(blocks, translatedBlocks []parser.Block) {
+	for i, b := range blocks {
+		if i >= len(translatedBlocks) {
+			break
+		}
+		if marked, ok := markedTranslation(b); ok {
+			translatedBlocks[i] = marked
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bilingual_pdf/internal/parser"
+)
+
+// usePseudoEngine sets the flags for an offline run with the pseudo engine
+// and restores them when the test ends.
+func usePseudoEngine(t *testing.T) {
+	t.Helper()
+	oldSource, oldTarget, oldEngine := sourceLang, targetLang, engineName
+	oldNoCache, oldSaveHTML, oldSaveTranslation := noCache, saveHTML, saveTranslation
+	oldFontSize, oldOutput, oldTranslation := fontSize, outputFile, translationFile
+	t.Cleanup(func() {
+		sourceLang, targetLang, engineName = oldSource, oldTarget, oldEngine
+		noCache, saveHTML, saveTranslation = oldNoCache, oldSaveHTML, oldSaveTranslation
+		fontSize, outputFile, translationFile = oldFontSize, oldOutput, oldTranslation
+	})
+
+	sourceLang, targetLang, engineName = "fr", "es", "pseudo"
+	noCache, saveHTML, saveTranslation = true, true, true
+	fontSize, outputFile, translationFile = "medium", "", ""
+}
+
+func copySample(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPipeline_PseudoEngine(t *testing.T) {
+	usePseudoEngine(t)
+	input := copySample(t, "sample.fr.md")
+
+	html, err := buildHTML(context.Background(), input)
+	if err != nil {
+		t.Fatalf("buildHTML failed: %v", err)
+	}
+
+	dir := filepath.Dir(input)
+	for _, name := range []string{"sample.fr.es.html", "sample.es.md"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to be saved: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sample.fr.es.checkpoint.json")); !os.IsNotExist(err) {
+		t.Errorf("checkpoint should be removed after a complete run")
+	}
+
+	if !strings.Contains(html, "[Ɓöñĵöûŕ ļé ɱöñðé") {
+		t.Errorf("rendered HTML should contain the pseudo-localized heading")
+	}
+	if !strings.Contains(html, `print(f&#34;Bonjour, {nom}!&#34;)`) && !strings.Contains(html, `print(f&quot;Bonjour, {nom}!&quot;)`) {
+		t.Errorf("code blocks should not be translated")
+	}
+
+	// The saved translation must keep the block structure of the source.
+	src, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tgt, err := os.ReadFile(filepath.Join(dir, "sample.es.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srcBlocks, _ := parser.Parse(src)
+	tgtBlocks, _ := parser.Parse(tgt)
+	if len(srcBlocks) != len(tgtBlocks) {
+		t.Fatalf("translation has %d blocks, source has %d", len(tgtBlocks), len(srcBlocks))
+	}
+	for i := range srcBlocks {
+		if srcBlocks[i].Kind != tgtBlocks[i].Kind || srcBlocks[i].Level != tgtBlocks[i].Level {
+			t.Errorf("block %d: %v/%d became %v/%d", i,
+				srcBlocks[i].Kind, srcBlocks[i].Level, tgtBlocks[i].Kind, tgtBlocks[i].Level)
+		}
+	}
+}
+
+func TestPipeline_PseudoEngineKeepsInlineMarkdown(t *testing.T) {
+	usePseudoEngine(t)
+	saveHTML, saveTranslation = false, false
+
+	input := filepath.Join(t.TempDir(), "links.md")
+	md := "Lisez [la documentation](https://example.com/docs) et lancez `make test`.\n\n" +
+		"- Un **premier** point\n- Un second avec <kbd>Entrée</kbd>\n"
+	if err := os.WriteFile(input, []byte(md), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	html, err := buildHTML(context.Background(), input)
+	if err != nil {
+		t.Fatalf("buildHTML failed: %v", err)
+	}
+	for _, want := range []string{
+		`<a href="https://example.com/docs">ļá ðöçûɱéñţáţíöñ</a>`,
+		`<code>make test</code>`,
+		`<strong>þŕéɱíéŕ</strong>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("rendered HTML is missing %s", want)
+		}
+	}
+}
+
+func TestPipeline_Markers(t *testing.T) {
+	usePseudoEngine(t)
+	input := filepath.Join(t.TempDir(), "markers.fr.md")
+	src := "<!-- notranslate -->\nGarder tel quel.\n\n<!-- translate: Hola a todos. -->\nBonjour à tous.\n\nBienvenue chez [Acme]{.notranslate} et [chat]{translation=\"gato\"}.\n"
+	if err := os.WriteFile(input, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	html, err := buildHTML(context.Background(), input)
+	if err != nil {
+		t.Fatalf("buildHTML failed: %v", err)
+	}
+	tgt, err := os.ReadFile(filepath.Join(filepath.Dir(input), "markers.es.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Garder tel quel.", "Hola a todos.", "Acme", "gato"} {
+		if !strings.Contains(string(tgt), want) {
+			t.Errorf("translation should contain %q, got:\n%s", want, tgt)
+		}
+	}
+	if strings.Contains(html, "notranslate") || strings.Contains(html, "translation=") {
+		t.Errorf("marker attributes should not be rendered")
+	}
+}
+
+func TestPipeline_Images(t *testing.T) {
+	usePseudoEngine(t)
+	dir := t.TempDir()
+	input := filepath.Join(dir, "images.fr.md")
+	src := "![Le schéma](schema.png \"Vue d'ensemble\")\n\nUn logo ![logo](https://example.com/logo.png) en ligne.\n"
+	if err := os.WriteFile(input, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"schema.png": "fr", "schema.es.png": "es"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	html, err := buildHTML(context.Background(), input)
+	if err != nil {
+		t.Fatalf("buildHTML failed: %v", err)
+	}
+	for _, want := range []string{
+		`src="data:image/png;base64,ZnI="`, // schema.png in the source column
+		`src="data:image/png;base64,ZXM="`, // schema.es.png in the target column
+		`src="https://example.com/logo.png"`,
+		`alt="Ļé šçĥéɱá" title="Ṽûé`, // translated alt text and title
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("rendered HTML should contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"bilingual_pdf/internal/languages"
+	"bilingual_pdf/internal/naming"
+	"bilingual_pdf/internal/parser"
+	"bilingual_pdf/internal/po"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	translationPO string
+	poOutput      string
+)
+
+var exportPOCmd = &cobra.Command{
+	Use:   "export-po input.md",
+	Short: "Export the blocks of a markdown file as a gettext PO file",
+	Long: `Writes every translatable block of the document as a PO entry, for
+Weblate, Poedit and other gettext tools. The msgid is the markdown source of
+the block, the msgctxt its index and kind, and a reference comment gives its
+line. Code blocks, thematic breaks and blocks marked notranslate or given
+a fixed translation are left out. Read the translated file
+back with --translation-po.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExportPO,
+}
+
+func init() {
+	exportPOCmd.Flags().StringVarP(&sourceLang, "source", "s", "fr", "source language code, or auto to detect it")
+	exportPOCmd.Flags().StringVarP(&targetLang, "target", "t", "es", "target language code")
+	exportPOCmd.Flags().StringVarP(&poOutput, "output", "o", "", "output PO filename")
+	rootCmd.AddCommand(exportPOCmd)
+}
+
+func runExportPO(cmd *cobra.Command, args []string) error {
+	inputFile := args[0]
+	if ext := filepath.Ext(inputFile); strings.ToLower(ext) != ".md" {
+		return fmt.Errorf("input file must have .md extension, got %q", ext)
+	}
+	if err := loadFrontMatter(cmd, inputFile); err != nil {
+		return err
+	}
+	if poOutput != "" {
+		if ext := filepath.Ext(poOutput); strings.ToLower(ext) != ".po" {
+			return fmt.Errorf("--output file must have .po extension, got %q", ext)
+		}
+	}
+	if sourceLang != languages.Auto {
+		if err := languages.Validate(sourceLang); err != nil {
+			return fmt.Errorf("invalid source language: %w", err)
+		}
+	}
+	if err := languages.Validate(targetLang); err != nil {
+		return fmt.Errorf("invalid target language: %w", err)
+	}
+
+	blocks, err := readAndParse(inputFile)
+	if err != nil {
+		return err
+	}
+	if sourceLang == languages.Auto {
+		// exports need no engine, so the language is detected offline
+		if err := detectSource(cmd.Context(), blocks, ""); err != nil {
+			return err
+		}
+	}
+
+	file := poFile(inputFile, blocks)
+	path := poOutput
+	if path == "" {
+		path = naming.POName(inputFile, sourceLang, targetLang, "")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("saving PO file: %w", err)
+	}
+	if err := file.Write(f); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("saving PO file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("saving PO file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Saved PO file: %s (%d entries)\n", path, len(file.Entries))
+	return nil
+}
+
+// poContext returns the msgctxt of block i: its index and kind.
+func poContext(i int, b parser.Block) string {
+	return fmt.Sprintf("%d %s", i, b.Kind)
+}
+
+// poTranslatable reports whether a block is exported to PO.
+func poTranslatable(b parser.Block) bool {
+	switch {
+	case b.Kind == parser.BlockCodeBlock, b.Kind == parser.BlockThematicBreak:
+		return false
+	case b.NoTranslate, b.Translation != "":
+		return false
+	}
+	return strings.TrimSpace(b.Raw) != ""
+}
+
+// poFile returns the PO entries of the translatable blocks.
+func poFile(inputFile string, blocks []parser.Block) *po.File {
+	f := &po.File{Header: []po.Field{
+		{Name: "Project-Id-Version", Value: filepath.Base(inputFile)},
+		{Name: "Language", Value: targetLang},
+		{Name: "MIME-Version", Value: "1.0"},
+		{Name: "Content-Type", Value: "text/plain; charset=UTF-8"},
+		{Name: "Content-Transfer-Encoding", Value: "8bit"},
+		{Name: "X-Source-Language", Value: sourceLang},
+		{Name: "X-Generator", Value: "bilingual_pdf " + Version},
+	}}
+	for i, b := range blocks {
+		if !poTranslatable(b) {
+			continue
+		}
+		e := po.Entry{Context: poContext(i, b), ID: b.Raw}
+		if b.Line > 0 {
+			e.References = []string{filepath.Base(inputFile) + ":" + strconv.Itoa(b.Line)}
+		}
+		f.Entries = append(f.Entries, e)
+	}
+	return f
+}
+
+// translateFromPO reads the translations of the blocks from a PO file,
+// matching entries to blocks by msgctxt and msgid with a unitMatcher. The
+// msgstr is the markdown of the translated block. Fuzzy translations are
+// used, but marked in the rendered output.
+func translateFromPO(path string, blocks []parser.Block) ([]parser.Block, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading PO file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	file, err := po.Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("parsing PO file %s: %w", path, err)
+	}
+	if lang := file.HeaderValue("X-Source-Language"); lang != "" && !sameLanguage(lang, sourceLang) {
+		fmt.Fprintf(os.Stderr, "Warning: %s is from %s, not %s\n", path, lang, sourceLang)
+	}
+	if lang := file.HeaderValue("Language"); lang != "" && !sameLanguage(lang, targetLang) {
+		fmt.Fprintf(os.Stderr, "Warning: %s is a translation to %s, not %s\n", path, lang, targetLang)
+	}
+
+	ids := make([]string, len(file.Entries))
+	sources := make([]string, len(file.Entries))
+	for j, e := range file.Entries {
+		ids[j], sources[j] = e.Context, e.ID
+	}
+	m := newUnitMatcher(ids, sources)
+
+	result := make([]parser.Block, len(blocks))
+	var changed, untranslated, fuzzy []string
+	for i, b := range blocks {
+		if marked, ok := markedTranslation(b); ok {
+			result[i] = marked
+			continue
+		}
+		if !poTranslatable(b) {
+			result[i] = b
+			continue
+		}
+		result[i] = parser.Block{Kind: b.Kind}
+		j := m.match(poContext(i, b), b.Raw)
+		if j < 0 {
+			changed = append(changed, fmt.Sprint(i))
+			continue
+		}
+		e := file.Entries[j]
+		if strings.TrimSpace(e.Str) == "" {
+			untranslated = append(untranslated, fmt.Sprint(i))
+			continue
+		}
+		result[i] = parseTranslatedBlock(b.Kind, e.Str)
+		if e.Fuzzy() {
+			fuzzy = append(fuzzy, fmt.Sprint(i))
+			result[i].HTML = `<div class="fuzzy">` + result[i].HTML + "</div>\n"
+		}
+	}
+
+	if len(changed) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d block(s) changed since the PO export and are left untranslated: %s\n",
+			len(changed), strings.Join(changed, ", "))
+	}
+	if len(untranslated) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d block(s) have no translation in %s: %s\n",
+			len(untranslated), path, strings.Join(untranslated, ", "))
+	}
+	if len(fuzzy) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d fuzzy translation(s) to review, marked in the output: %s\n",
+			len(fuzzy), strings.Join(fuzzy, ", "))
+	}
+	return result, nil
+}
+
+// parseTranslatedBlock returns the block of a translation in markdown. A
+// translation that parses into several blocks is kept in one.
+func parseTranslatedBlock(kind parser.BlockKind, md string) parser.Block {
+	tBlocks, err := parser.Parse([]byte(md))
+	if err != nil || len(tBlocks) == 0 {
+		return parser.Block{
+			Kind: kind,
+			Text: md,
+			Raw:  md,
+			HTML: "<p>" + template.HTMLEscapeString(md) + "</p>\n",
+		}
+	}
+	if len(tBlocks) == 1 {
+		return tBlocks[0]
+	}
+	b := parser.Block{Kind: tBlocks[0].Kind, Level: tBlocks[0].Level, Raw: strings.TrimRight(md, "\n")}
+	var texts []string
+	for _, t := range tBlocks {
+		b.HTML += t.HTML
+		texts = append(texts, t.Text)
+	}
+	b.Text = strings.Join(texts, "\n\n")
+	return b
+}
+
+// sameLanguage reports whether a gettext language such as "es_ES" is the
+// language code.
+func sameLanguage(lang, code string) bool {
+	primary, _, _ := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-")
+	return strings.EqualFold(primary, code)
+}
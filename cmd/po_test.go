@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"bilingual_pdf/internal/parser"
+	"bilingual_pdf/internal/po"
+)
+
+func TestPOFile(t *testing.T) {
+	oldSource, oldTarget := sourceLang, targetLang
+	defer func() { sourceLang, targetLang = oldSource, oldTarget }()
+	sourceLang, targetLang = "fr", "es"
+
+	blocks, _ := parser.Parse([]byte("# Titre\n\n```\ncode\n```\n\n---\n\n- un\n- deux\n"))
+	f := poFile("dir/doc.fr.md", blocks)
+
+	if f.HeaderValue("Language") != "es" || f.HeaderValue("X-Source-Language") != "fr" {
+		t.Errorf("unexpected header %+v", f.Header)
+	}
+	if len(f.Entries) != 2 {
+		t.Fatalf("code blocks and thematic breaks should be left out, got %+v", f.Entries)
+	}
+	want := []po.Entry{
+		{Context: "0 Heading", ID: "# Titre", References: []string{"doc.fr.md:1"}},
+		{Context: "3 List", ID: "- un\n- deux\n", References: []string{"doc.fr.md:9"}},
+	}
+	for i, e := range f.Entries {
+		if e.Context != want[i].Context || e.ID != want[i].ID || !slices.Equal(e.References, want[i].References) {
+			t.Errorf("entry %d = %+v, want %+v", i, e, want[i])
+		}
+	}
+}
+
+func TestTranslateFromPO(t *testing.T) {
+	oldSource, oldTarget := sourceLang, targetLang
+	defer func() { sourceLang, targetLang = oldSource, oldTarget }()
+	sourceLang, targetLang = "fr", "es"
+
+	blocks, _ := parser.Parse([]byte("# Titre\n\nLisez [le guide](https://example.com).\n\n```\ncode\n```\n\nSans traduction.\n"))
+	f := poFile("doc.fr.md", blocks)
+	f.Entries[0].Str = "# Título"
+	f.Entries[1].Str = "Lea [la guía](https://example.com)."
+	f.Entries[1].Flags = []string{"fuzzy"}
+
+	path := filepath.Join(t.TempDir(), "doc.fr.es.po")
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Write(out); err != nil {
+		t.Fatal(err)
+	}
+	_ = out.Close()
+
+	got, err := translateFromPO(path, blocks)
+	if err != nil {
+		t.Fatalf("translateFromPO failed: %v", err)
+	}
+	if len(got) != len(blocks) {
+		t.Fatalf("expected %d blocks, got %d", len(blocks), len(got))
+	}
+	if got[0].Kind != parser.BlockHeading || got[0].Text != "Título" {
+		t.Errorf("unexpected heading %+v", got[0])
+	}
+	if !strings.HasPrefix(got[1].HTML, `<div class="fuzzy">`) ||
+		!strings.Contains(got[1].HTML, `<a href="https://example.com">la guía</a>`) {
+		t.Errorf("fuzzy translation should be used and marked, got %q", got[1].HTML)
+	}
+	if strings.Contains(got[0].HTML, "fuzzy") {
+		t.Errorf("reviewed translation should not be marked, got %q", got[0].HTML)
+	}
+	if got[2].Raw != blocks[2].Raw {
+		t.Errorf("code block should be kept, got %q", got[2].Raw)
+	}
+	if got[3].HTML != "" {
+		t.Errorf("untranslated block should be empty, got %q", got[3].HTML)
+	}
+}
+
+func TestParseTranslatedBlock_SeveralBlocks(t *testing.T) {
+	b := parseTranslatedBlock(parser.BlockParagraph, "Una frase.\n\nOtra frase.\n")
+	if b.Kind != parser.BlockParagraph || b.Text != "Una frase.\n\nOtra frase." {
+		t.Errorf("unexpected block %+v", b)
+	}
+	if b.HTML != "<p>Una frase.</p>\n<p>Otra frase.</p>\n" {
+		t.Errorf("unexpected HTML %q", b.HTML)
+	}
+}
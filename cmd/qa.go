@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"bilingual_pdf/internal/naming"
+	"bilingual_pdf/internal/parser"
+	"bilingual_pdf/internal/qa"
+	"bilingual_pdf/internal/translator"
+)
+
+// qaModes lists the accepted --qa values.
+var qaModes = []string{"backtranslate"}
+
+// maybeRunQA translates the translated blocks back into the source language
+// with the engine, prints a table of similarity scores and saves the JSON
+// report next to the output.
+func maybeRunQA(ctx context.Context, inputFile string, blocks, translatedBlocks []parser.Block) error {
+	if qaMode == "" {
+		return nil
+	}
+
+	tr, err := translator.New(engineName, engineConfig())
+	if err != nil {
+		return err
+	}
+	if limit := batchLimit(engineName); limit > 0 {
+		tr = translator.NewBatchTranslator(tr, limit, os.Stderr)
+	}
+
+	var indices []int
+	var texts []string
+	for i, b := range blocks {
+		if i >= len(translatedBlocks) || !isDetectable(b) {
+			continue
+		}
+		if strings.TrimSpace(b.Text) == "" || strings.TrimSpace(translatedBlocks[i].Text) == "" {
+			continue
+		}
+		indices = append(indices, i)
+		texts = append(texts, translatedBlocks[i].Text)
+	}
+
+	fmt.Fprintf(os.Stderr, "Back-translating %d blocks for the quality check...\n", len(texts))
+	back, err := tr.Translate(ctx, texts, targetLang, sourceLang)
+	if err != nil {
+		return fmt.Errorf("back-translating: %w", err)
+	}
+
+	report := qa.NewReport(sourceLang, targetLang, engineName, qaThreshold)
+	for j, i := range indices {
+		report.Add(qa.Block{
+			Index:           i,
+			Kind:            blocks[i].Kind.String(),
+			Source:          blocks[i].Text,
+			Translation:     translatedBlocks[i].Text,
+			BackTranslation: back[j],
+		})
+	}
+	report.WriteTable(os.Stdout)
+
+	path := naming.QAReportName(inputFile, sourceLang, targetLang, outputFile)
+	if err := report.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Saved QA report: %s\n", path)
+	return nil
+}
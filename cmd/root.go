@@ -1,18 +1,28 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
+	"bilingual_pdf/internal/cache"
 	"bilingual_pdf/internal/converter"
+	"bilingual_pdf/internal/glossary"
+	"bilingual_pdf/internal/images"
 	"bilingual_pdf/internal/languages"
 	"bilingual_pdf/internal/naming"
 	"bilingual_pdf/internal/parser"
+	"bilingual_pdf/internal/qa"
 	"bilingual_pdf/internal/renderer"
 	"bilingual_pdf/internal/translator"
 
@@ -28,10 +38,27 @@ var (
 	translationFile string
 	outputFile      string
 	fontSize        string
+	pageSize        string
 	saveHTML        bool
 	saveTranslation bool
 	listLanguages   bool
 	attribution     bool
+	engineName      string
+	engineOptions   map[string]string
+	listEngines     bool
+	noCache         bool
+	refreshCache    bool
+	workers         int
+	rateLimit       float64
+	retries         int
+	timeout         time.Duration
+	glossaryFile    string
+	noMask          bool
+	batchChars      int
+	noBatch         bool
+	qaMode          string
+	qaThreshold     float64
+	outsideImages   bool
 )
 
 var rootCmd = &cobra.Command{
@@ -40,22 +67,45 @@ var rootCmd = &cobra.Command{
 	Long: `Converts a markdown document into a side-by-side bilingual PDF
 with the source language in the left column and its translation
 in the right column. Supports any language pair available through
-Google Translate. Defaults to French → Spanish.`,
+Google Translate or another registered engine (see --list-engines).
+Defaults to French → Spanish.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runPipeline,
 }
 
 func init() {
 	rootCmd.Version = Version
-	rootCmd.Flags().StringVarP(&sourceLang, "source", "s", "fr", "source language code")
+	rootCmd.Flags().StringVarP(&sourceLang, "source", "s", "fr", "source language code, or auto to detect it")
 	rootCmd.Flags().StringVarP(&targetLang, "target", "t", "es", "target language code")
 	rootCmd.Flags().StringVar(&translationFile, "translation", "", "path to pre-translated markdown file")
+	rootCmd.Flags().StringVar(&translationXLIFF, "translation-xliff", "", "path to an XLIFF 2.0 file translated from export-xliff")
+	rootCmd.Flags().StringVar(&translationPO, "translation-po", "", "path to a gettext PO file translated from export-po")
 	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "output PDF filename")
 	rootCmd.Flags().StringVar(&fontSize, "font-size", renderer.DefaultFontSize, "font size preset: small, medium, or large")
+	rootCmd.Flags().StringVar(&pageSize, "page-size", renderer.DefaultPageSize, "page size: "+strings.Join(renderer.PageSizes, ", "))
 	rootCmd.Flags().BoolVar(&saveHTML, "html", false, "also save the generated HTML")
 	rootCmd.Flags().BoolVar(&saveTranslation, "save-translation", false, "also save the translation markdown")
+	rootCmd.Flags().BoolVar(&saveTMX, "save-tmx", false, "also save the aligned blocks as a TMX translation memory")
 	rootCmd.Flags().BoolVar(&listLanguages, "list-languages", false, "list supported language codes")
 	rootCmd.Flags().BoolVarP(&attribution, "attribution", "a", false, "append attribution line to output")
+	rootCmd.Flags().StringVarP(&engineName, "engine", "e", translator.DefaultEngine, "translation engine (see --list-engines)")
+	rootCmd.Flags().StringToStringVar(&engineOptions, "engine-opt", nil, "engine-specific option as key=value (repeatable)")
+	rootCmd.Flags().BoolVar(&listEngines, "list-engines", false, "list available translation engines")
+	rootCmd.Flags().IntVar(&workers, "workers", 0, "maximum concurrent translation requests (0 for the engine default)")
+	rootCmd.Flags().Float64Var(&rateLimit, "rate-limit", 0, "maximum translation requests per second (0 for the engine default)")
+	rootCmd.Flags().IntVar(&retries, "retries", 3, "retries of a failed translation request (with exponential backoff)")
+	rootCmd.Flags().DurationVar(&timeout, "timeout", translator.DefaultTimeout, "time limit of a single translation request")
+	rootCmd.Flags().IntVar(&batchChars, "batch-chars", 0, "maximum characters per batched translation request (0 for the engine default)")
+	rootCmd.Flags().BoolVar(&noBatch, "no-batch", false, "send one block per translation request")
+	rootCmd.Flags().StringArrayVar(&tmFiles, "tm", nil, "TMX translation memory consulted before the engine (repeatable)")
+	rootCmd.Flags().StringVar(&glossaryFile, "glossary", "", "glossary of forced and do-not-translate terms (.csv, .tsv or .yaml)")
+	rootCmd.Flags().BoolVar(&noMask, "no-mask", false, "send code spans, URLs, link targets and HTML tags to the engine unprotected")
+	rootCmd.Flags().StringVar(&granularity, "granularity", "block", "rows of the output: block, or sentence to split paragraphs and list items")
+	rootCmd.Flags().StringVar(&qaMode, "qa", "", "quality check of the translation: backtranslate")
+	rootCmd.Flags().Float64Var(&qaThreshold, "qa-threshold", qa.DefaultThreshold, "similarity (0-1) below which --qa flags a block")
+	rootCmd.Flags().BoolVar(&outsideImages, "allow-outside-images", false, "embed images outside the directory of the input file")
+	rootCmd.Flags().BoolVar(&noCache, "no-cache", false, "do not use the translation cache")
+	rootCmd.Flags().BoolVar(&refreshCache, "refresh-cache", false, "retranslate all blocks and update the translation cache")
 }
 
 func Execute() {
@@ -69,54 +119,115 @@ func runPipeline(cmd *cobra.Command, args []string) error {
 		languages.PrintSupported(os.Stdout)
 		return nil
 	}
+	if listEngines {
+		translator.PrintEngines(os.Stdout)
+		return nil
+	}
 
-	inputFile, err := validateArgs(args)
+	inputFile, err := validateArgs(cmd, args)
 	if err != nil {
 		return err
 	}
 	printWarnings()
 
+	// Ctrl-C or SIGTERM stops the translation; the blocks completed so far
+	// are kept in the checkpoint and the cache. A second signal kills the
+	// process.
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+
+	htmlContent, err := buildHTML(ctx, inputFile)
+	if err != nil {
+		return err
+	}
+
+	// 6. Convert to PDF and write
+	return generatePDF(inputFile, htmlContent)
+}
+
+// buildHTML runs the pipeline up to the rendered HTML: parsing, translation,
+// the optional translation, QA and HTML outputs, and rendering.
+func buildHTML(ctx context.Context, inputFile string) (string, error) {
 	// 1. Read and parse input
 	blocks, err := readAndParse(inputFile)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	if sourceLang == languages.Auto {
+		engine := engineName
+		if len(importFlags()) > 0 {
+			engine = ""
+		}
+		if err := detectSource(ctx, blocks, engine); err != nil {
+			return "", err
+		}
 	}
 
-	// 2. Translate
-	translatedBlocks, err := translateAll(blocks)
+	// 2. Translate, sentence by sentence in sentence granularity; a
+	// translation read from a file is split into sentences afterwards
+	var rows []sentenceRow
+	if granularity == "sentence" && len(importFlags()) == 0 {
+		blocks, rows = splitSentences(blocks, sourceLang)
+	}
+	blocks, translatedBlocks, err := translateAll(ctx, inputFile, blocks)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	// 3. Save translation markdown if requested
-	if err := maybeSaveTranslation(inputFile, blocks, translatedBlocks); err != nil {
-		return err
+	// 3. Save translation markdown if requested, block by block
+	if rows != nil {
+		err = maybeSaveTranslation(inputFile, joinSentences(rows, blocks), joinSentences(rows, translatedBlocks))
+	} else {
+		err = maybeSaveTranslation(inputFile, blocks, translatedBlocks)
+	}
+	if err != nil {
+		return "", err
+	}
+	if granularity == "sentence" && len(importFlags()) > 0 {
+		blocks, translatedBlocks, rows = alignSentences(blocks, translatedBlocks)
+	}
+
+	// 3a. Save the aligned blocks as TMX if requested
+	if err := maybeSaveTMX(inputFile, blocks, translatedBlocks); err != nil {
+		return "", err
+	}
+
+	// 3b. Check the translation by back-translation if requested
+	if err := maybeRunQA(ctx, inputFile, blocks, translatedBlocks); err != nil {
+		return "", err
 	}
 
 	// 4. Render HTML
 	pairs := buildPairs(blocks, translatedBlocks)
-	htmlContent, err := renderer.Render(renderer.TemplateData{
-		Title:       fmt.Sprintf("Bilingual: %s → %s", languages.Name(sourceLang), languages.Name(targetLang)),
+	embedImages(inputFile, pairs)
+	decorateSentences(pairs, rows)
+	data := renderer.TemplateData{
+		Title:       documentTitle(languages.Name(sourceLang), languages.Name(targetLang)),
 		SourceLabel: languages.NativeName(sourceLang),
 		TargetLabel: languages.NativeName(targetLang),
 		Pairs:       pairs,
 		Fonts:       renderer.FontSizePresets[fontSize],
+		PageSize:    pageSize,
 		Attribution: attribution,
-	})
+	}
+	if frontMatter != nil {
+		data.Author, data.Date = frontMatter.Author, frontMatter.Date
+	}
+	htmlContent, err := renderer.Render(data)
 	if err != nil {
-		return fmt.Errorf("rendering HTML: %w", err)
+		return "", fmt.Errorf("rendering HTML: %w", err)
 	}
 
 	// 5. Save HTML if requested
 	if err := maybeSaveHTML(inputFile, htmlContent); err != nil {
-		return err
+		return "", err
 	}
-
-	// 6. Convert to PDF and write
-	return generatePDF(inputFile, htmlContent)
+	return htmlContent, nil
 }
 
-func validateArgs(args []string) (string, error) {
+func validateArgs(cmd *cobra.Command, args []string) (string, error) {
 	if len(args) == 0 {
 		return "", fmt.Errorf("input markdown file is required (use --help for usage)")
 	}
@@ -128,6 +239,9 @@ func validateArgs(args []string) (string, error) {
 	if _, err := os.Stat(inputFile); os.IsNotExist(err) {
 		return "", fmt.Errorf("input file not found: %s", inputFile)
 	}
+	if err := loadFrontMatter(cmd, inputFile); err != nil {
+		return "", err
+	}
 	if translationFile != "" {
 		if ext := filepath.Ext(translationFile); strings.ToLower(ext) != ".md" {
 			return "", fmt.Errorf("--translation file must have .md extension, got %q", ext)
@@ -136,6 +250,33 @@ func validateArgs(args []string) (string, error) {
 			return "", fmt.Errorf("translation file not found: %s", translationFile)
 		}
 	}
+	if flags := importFlags(); len(flags) > 1 {
+		return "", fmt.Errorf("%s cannot be used together", strings.Join(flags, " and "))
+	}
+	if translationXLIFF != "" {
+		if err := validateXLIFFExt("--translation-xliff", translationXLIFF); err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(translationXLIFF); os.IsNotExist(err) {
+			return "", fmt.Errorf("XLIFF file not found: %s", translationXLIFF)
+		}
+	}
+	if translationPO != "" {
+		if ext := filepath.Ext(translationPO); strings.ToLower(ext) != ".po" {
+			return "", fmt.Errorf("--translation-po file must have .po extension, got %q", ext)
+		}
+		if _, err := os.Stat(translationPO); os.IsNotExist(err) {
+			return "", fmt.Errorf("PO file not found: %s", translationPO)
+		}
+	}
+	if glossaryFile != "" {
+		if _, err := os.Stat(glossaryFile); os.IsNotExist(err) {
+			return "", fmt.Errorf("glossary file not found: %s", glossaryFile)
+		}
+	}
+	if err := validateTMXFiles(); err != nil {
+		return "", err
+	}
 	if outputFile != "" {
 		if ext := filepath.Ext(outputFile); strings.ToLower(ext) != ".pdf" {
 			return "", fmt.Errorf("--output file must have .pdf extension, got %q", ext)
@@ -144,8 +285,51 @@ func validateArgs(args []string) (string, error) {
 	if _, ok := renderer.FontSizePresets[fontSize]; !ok {
 		return "", fmt.Errorf("invalid --font-size %q: must be small, medium, or large", fontSize)
 	}
-	if err := languages.Validate(sourceLang); err != nil {
-		return "", fmt.Errorf("invalid source language: %w", err)
+	pageSize = strings.ToLower(pageSize)
+	if !slices.Contains(renderer.PageSizes, pageSize) {
+		return "", fmt.Errorf("invalid --page-size %q: must be %s", pageSize, strings.Join(renderer.PageSizes, ", "))
+	}
+	if workers < 0 {
+		return "", fmt.Errorf("invalid --workers %d: must not be negative", workers)
+	}
+	if batchChars < 0 {
+		return "", fmt.Errorf("invalid --batch-chars %d: must not be negative", batchChars)
+	}
+	if retries < 0 {
+		return "", fmt.Errorf("invalid --retries %d: must not be negative", retries)
+	}
+	if timeout < 0 {
+		return "", fmt.Errorf("invalid --timeout %s: must not be negative", timeout)
+	}
+	if rateLimit < 0 {
+		return "", fmt.Errorf("invalid --rate-limit %g: must not be negative", rateLimit)
+	}
+	engine, err := translator.Lookup(engineName)
+	if err != nil {
+		return "", fmt.Errorf("invalid --engine: %w", err)
+	}
+	if err := engine.CheckOptions(engineOptions); err != nil {
+		return "", fmt.Errorf("invalid --engine-opt: %w", err)
+	}
+	if engineName == "file" && translationFile == "" {
+		return "", fmt.Errorf("--engine file requires --translation <file.md>")
+	}
+	if !slices.Contains(granularities, granularity) {
+		return "", fmt.Errorf("invalid --granularity %q: must be %s", granularity, strings.Join(granularities, " or "))
+	}
+	if qaMode != "" && !slices.Contains(qaModes, qaMode) {
+		return "", fmt.Errorf("invalid --qa %q: must be %s", qaMode, strings.Join(qaModes, " or "))
+	}
+	if qaMode != "" && engineName == "file" {
+		return "", fmt.Errorf("--qa %s requires a translation engine, not --engine file", qaMode)
+	}
+	if qaThreshold < 0 || qaThreshold > 1 {
+		return "", fmt.Errorf("invalid --qa-threshold %g: must be between 0 and 1", qaThreshold)
+	}
+	if sourceLang != languages.Auto {
+		if err := languages.Validate(sourceLang); err != nil {
+			return "", fmt.Errorf("invalid source language: %w", err)
+		}
 	}
 	if err := languages.Validate(targetLang); err != nil {
 		return "", fmt.Errorf("invalid target language: %w", err)
@@ -160,6 +344,39 @@ func printWarnings() {
 	if translationFile != "" && saveTranslation {
 		fmt.Fprintln(os.Stderr, "Warning: --save-translation is ignored when --translation is provided")
 	}
+	if noCache && refreshCache {
+		fmt.Fprintln(os.Stderr, "Warning: --refresh-cache is ignored when --no-cache is provided")
+	}
+	if translationFile != "" && engineName != "file" && engineName != translator.DefaultEngine {
+		fmt.Fprintf(os.Stderr, "Warning: --engine %s is ignored when --translation is provided\n", engineName)
+	}
+	for _, flag := range importFlags() {
+		if glossaryFile != "" {
+			fmt.Fprintf(os.Stderr, "Warning: --glossary is ignored when %s is provided\n", flag)
+		}
+		if len(tmFiles) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: --tm is ignored when %s is provided\n", flag)
+		}
+		if flag != "--translation" && engineName != translator.DefaultEngine && qaMode == "" {
+			fmt.Fprintf(os.Stderr, "Warning: --engine %s is ignored when %s is provided\n", engineName, flag)
+		}
+	}
+}
+
+// importFlags returns the flags given for a translation read from a file
+// instead of translated by an engine.
+func importFlags() []string {
+	var flags []string
+	if translationFile != "" {
+		flags = append(flags, "--translation")
+	}
+	if translationXLIFF != "" {
+		flags = append(flags, "--translation-xliff")
+	}
+	if translationPO != "" {
+		flags = append(flags, "--translation-po")
+	}
+	return flags
 }
 
 func readAndParse(inputFile string) ([]parser.Block, error) {
@@ -179,47 +396,222 @@ func readAndParse(inputFile string) ([]parser.Block, error) {
 	return blocks, nil
 }
 
-func translateAll(blocks []parser.Block) ([]parser.Block, error) {
+// translateAll translates the blocks. It returns the rows of the output: the
+// source blocks, which may have gaps where a pre-translated file has blocks
+// of its own, and their translations.
+func translateAll(ctx context.Context, inputFile string, blocks []parser.Block) ([]parser.Block, []parser.Block, error) {
+	if translationXLIFF != "" {
+		translated, err := translateFromXLIFF(translationXLIFF, blocks)
+		return blocks, translated, err
+	}
+	if translationPO != "" {
+		translated, err := translateFromPO(translationPO, blocks)
+		return blocks, translated, err
+	}
+	name, cfg := engineName, engineConfig()
 	if translationFile != "" {
-		return translateFromFile(blocks)
+		// the engine options are for the engine of --qa
+		name, cfg.Options = "file", nil
+	}
+	tr, err := translator.New(name, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bt, ok := tr.(translator.BlockTranslator); ok {
+		source, translated, err := translateBlocks(ctx, bt, blocks)
+		applyMarkers(source, translated)
+		return source, translated, err
+	}
+	if limit := batchLimit(name); limit > 0 {
+		tr = translator.NewBatchTranslator(tr, limit, os.Stderr)
+	}
+	checkpoint := naming.CheckpointName(inputFile, sourceLang, targetLang, outputFile)
+	tr = translator.NewCheckpointTranslator(tr, checkpoint, name, os.Stderr)
+	if !noCache {
+		if tr, err = withCache(tr, name); err != nil {
+			return nil, nil, err
+		}
+	}
+	if glossaryFile != "" {
+		g, err := glossary.Load(glossaryFile, sourceLang, targetLang)
+		if err != nil {
+			return nil, nil, err
+		}
+		tr = translator.NewGlossaryTranslator(tr, g, os.Stderr)
+	}
+	if !noMask {
+		tr = translator.NewMaskingTranslator(tr, os.Stderr)
+	}
+	tr = phraseTranslator{Inner: tr}
+	if len(tmFiles) > 0 {
+		// The memory holds the source text of the blocks, marked
+		// phrases included, and its translations are taken as they
+		// are, without the glossary.
+		mem, err := loadMemory()
+		if err != nil {
+			return nil, nil, err
+		}
+		tr = translator.NewMemoryTranslator(tr, mem, os.Stderr)
+	}
+	translated, err := translateWithEngine(ctx, tr, blocks)
+	return blocks, translated, err
+}
+
+// engineConfig returns the engine settings given on the command line.
+func engineConfig() translator.Config {
+	return translator.Config{
+		Progress: os.Stderr,
+		Warn:     os.Stderr,
+		Path:     translationFile,
+		Options:  engineOptions,
+
+		Workers:   workers,
+		RateLimit: rateLimit,
+		Retries:   retries,
+		Timeout:   timeout,
 	}
-	return translateWithGoogle(blocks)
 }
 
-func translateFromFile(blocks []parser.Block) ([]parser.Block, error) {
-	ft := translator.NewFileTranslator(translationFile, os.Stderr)
-	result, err := ft.TranslateBlocks(blocks)
+// batchLimit returns the maximum characters per batched request for the
+// engine, or 0 if blocks are to be sent one by one.
+func batchLimit(engine string) int {
+	if noBatch {
+		return 0
+	}
+	if batchChars > 0 {
+		return batchChars
+	}
+	e, err := translator.Lookup(engine)
+	if err != nil {
+		return 0
+	}
+	return e.BatchChars
+}
+
+// withCache wraps tr with the on-disk translation memory for the engine, its
+// options and the language pair. The glossary needs no part in the key: its
+// terms are masked before the text reaches the cache, and their
+// translations put back after it.
+func withCache(tr translator.Translator, engine string) (translator.Translator, error) {
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	e, err := translator.Lookup(engine)
+	if err != nil {
+		return nil, err
+	}
+	options, err := e.CacheOptions(engineOptions)
+	if err != nil {
+		return nil, err
+	}
+	table, err := cache.New(dir).Open(cache.Variant(engine, options), sourceLang, targetLang)
 	if err != nil {
-		return nil, fmt.Errorf("reading translation file: %w", err)
+		return nil, err
 	}
-	return result, nil
+	ct := translator.NewCachedTranslator(tr, table, os.Stderr)
+	ct.Refresh = refreshCache
+	return ct, nil
 }
 
-func translateWithGoogle(blocks []parser.Block) ([]parser.Block, error) {
-	gt := translator.NewGoogleTranslator(os.Stderr)
+func translateBlocks(ctx context.Context, bt translator.BlockTranslator, blocks []parser.Block) ([]parser.Block, []parser.Block, error) {
+	source, result, err := bt.TranslateBlocks(ctx, blocks)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading translation file: %w", err)
+	}
+	return source, result, nil
+}
 
-	texts := make([]string, len(blocks))
+// translateWithEngine translates the blocks with the engine, a table cell
+// by cell.
+func translateWithEngine(ctx context.Context, tr translator.Translator, blocks []parser.Block) ([]parser.Block, error) {
+	var texts []string
+	first := make([]int, len(blocks)+1) // index of the first text of each block
 	for i, b := range blocks {
-		switch b.Kind {
-		case parser.BlockCodeBlock:
-			// code blocks are never translated
-		case parser.BlockHTML:
-			// send raw HTML to Google Translate (it preserves tags)
-			texts[i] = b.Raw
-		case parser.BlockParagraph, parser.BlockList:
-			// send raw markdown so inline syntax ([links](url), **bold**) is preserved
-			texts[i] = b.Raw
-		default:
-			texts[i] = b.Text
-		}
+		first[i] = len(texts)
+		texts = append(texts, blockSegments(b)...)
 	}
+	first[len(blocks)] = len(texts)
 
-	translatedTexts, err := gt.Translate(texts, sourceLang, targetLang)
+	translatedTexts, err := tr.Translate(ctx, texts, sourceLang, targetLang)
+	if errors.Is(err, context.Canceled) {
+		return nil, fmt.Errorf("translation interrupted: %w", err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("translating: %w", err)
 	}
 
-	return buildTranslatedBlocks(blocks, translatedTexts), nil
+	blockTexts := make([]string, len(blocks))
+	for i, b := range blocks {
+		blockTexts[i] = joinSegments(b, translatedTexts[first[i]:first[i+1]])
+	}
+	return buildTranslatedBlocks(blocks, blockTexts), nil
+}
+
+// blockSegments returns the texts of a block to translate: the cells of a
+// table, the paragraphs of a list or quote at any depth, or the segmentText
+// of other blocks.
+func blockSegments(b parser.Block) []string {
+	switch {
+	case segmentText(b) == "":
+		return []string{""}
+	case b.Table != nil:
+		return b.Table.Cells()
+	case b.List != nil:
+		return b.List.Texts()
+	case b.Quote != nil:
+		return b.Quote.Texts()
+	}
+	return []string{segmentText(b)}
+}
+
+// joinSegments returns the translation of a block from the translations of
+// its blockSegments, as its segmentText would be translated: the translated
+// table or list in markdown, the content of the translated quote, or the
+// only segment.
+func joinSegments(b parser.Block, segments []string) string {
+	switch {
+	case segmentText(b) == "":
+		return segments[0]
+	case b.Table != nil:
+		return b.Table.WithCells(segments).Markdown()
+	case b.List != nil:
+		return b.List.WithTexts(segments).Markdown()
+	case b.Quote != nil:
+		return b.Quote.WithTexts(segments).Inner()
+	}
+	return segments[0]
+}
+
+// segmentText returns the text of a block to translate, or "" for blocks
+// that are never translated.
+func segmentText(b parser.Block) string {
+	if b.NoTranslate || b.Translation != "" {
+		// marked blocks are copied or given their fixed translation
+		return ""
+	}
+	switch b.Kind {
+	case parser.BlockCodeBlock:
+		// code blocks are never translated
+		return ""
+	case parser.BlockHTML:
+		// send raw HTML to the engine (Google Translate preserves tags)
+		return b.Raw
+	case parser.BlockParagraph, parser.BlockList, parser.BlockTable:
+		// send raw markdown so inline syntax ([links](url), **bold**) is preserved
+		return b.Raw
+	case parser.BlockHeading:
+		// the inline markdown of the heading, without its # marker
+		return strings.TrimPrefix(b.Raw, strings.Repeat("#", b.Level)+" ")
+	case parser.BlockBlockquote:
+		// the markdown inside the quote, without its > markers
+		if b.Quote != nil {
+			return b.Quote.Inner()
+		}
+		return b.Text
+	default:
+		return b.Text
+	}
 }
 
 func buildTranslatedBlocks(blocks []parser.Block, translatedTexts []string) []parser.Block {
@@ -229,6 +621,10 @@ func buildTranslatedBlocks(blocks []parser.Block, translatedTexts []string) []pa
 			result[i] = b
 			continue
 		}
+		if marked, ok := markedTranslation(b); ok {
+			result[i] = marked
+			continue
+		}
 		if b.Kind == parser.BlockHTML {
 			translated := translatedTexts[i]
 			if translated == "" {
@@ -297,6 +693,17 @@ func buildPairs(blocks, translatedBlocks []parser.Block) []renderer.BlockPair {
 	return pairs
 }
 
+// embedImages embeds the local images of the rows as data URIs, using the
+// variant of an image in the language of each column when there is one.
+func embedImages(inputFile string, pairs []renderer.BlockPair) {
+	e := images.NewEmbedder(filepath.Dir(inputFile), []string{sourceLang, targetLang}, os.Stderr)
+	e.AllowOutside = outsideImages
+	for i := range pairs {
+		pairs[i].Source = template.HTML(e.Embed(string(pairs[i].Source), sourceLang))
+		pairs[i].Target = template.HTML(e.Embed(string(pairs[i].Target), targetLang))
+	}
+}
+
 func maybeSaveHTML(inputFile, htmlContent string) error {
 	if !saveHTML {
 		return nil
@@ -314,6 +721,12 @@ func generatePDF(inputFile, htmlContent string) error {
 	if err != nil {
 		return fmt.Errorf("converting to PDF: %w", err)
 	}
+	info := pdfInfo(documentTitle(languages.Name(sourceLang), languages.Name(targetLang)))
+	if withInfo, err := converter.SetInfo(pdfBytes, info); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	} else {
+		pdfBytes = withInfo
+	}
 
 	pdfPath := naming.OutputName(inputFile, sourceLang, targetLang, outputFile)
 	if err := os.WriteFile(pdfPath, pdfBytes, 0644); err != nil {
@@ -334,14 +747,7 @@ func reconstructMarkdown(sourceBlock parser.Block, translatedText string) string
 		}
 		return prefix + text
 	case parser.BlockBlockquote:
-		lines := strings.Split(translatedText, "\n")
-		var buf strings.Builder
-		for _, line := range lines {
-			buf.WriteString("> ")
-			buf.WriteString(line)
-			buf.WriteString("\n")
-		}
-		return buf.String()
+		return parser.QuoteLines(translatedText)
 	case parser.BlockCodeBlock:
 		return sourceBlock.Raw
 	case parser.BlockHTML:
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"strings"
 	"testing"
 
@@ -52,3 +53,94 @@ func TestBuildTranslatedBlocks_ListWithLink(t *testing.T) {
 		t.Errorf("translated list HTML should contain <a> tag with href, got %q", b.HTML)
 	}
 }
+
+// upperTranslator records the texts it is given and returns them in upper
+// case.
+type upperTranslator struct {
+	texts []string
+}
+
+func (u *upperTranslator) Translate(_ context.Context, texts []string, _, _ string) ([]string, error) {
+	u.texts = append(u.texts, texts...)
+	result := make([]string, len(texts))
+	for i, text := range texts {
+		result[i] = strings.ToUpper(text)
+	}
+	return result, nil
+}
+
+func TestTranslateWithEngine_TableCells(t *testing.T) {
+	blocks, err := parser.Parse([]byte("| Nom | Prix |\n| :-- | --: |\n| chèvre | bon marché |\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := &upperTranslator{}
+	result, err := translateWithEngine(context.Background(), tr, blocks)
+	if err != nil {
+		t.Fatalf("translateWithEngine failed: %v", err)
+	}
+
+	want := []string{"Nom", "Prix", "chèvre", "bon marché"}
+	if strings.Join(tr.texts, ",") != strings.Join(want, ",") {
+		t.Errorf("cells should be translated one by one, got %q", tr.texts)
+	}
+	b := result[0]
+	if b.Kind != parser.BlockTable || b.Table == nil {
+		t.Fatalf("expected a translated table, got %+v", b)
+	}
+	if got := b.Table.Cells(); got[3] != "BON MARCHÉ" {
+		t.Errorf("translated cells = %q", got)
+	}
+	if !strings.Contains(b.HTML, `<td style="text-align:right">BON MARCHÉ</td>`) {
+		t.Errorf("translated table should keep the alignment, got %q", b.HTML)
+	}
+}
+
+func TestTranslateWithEngine_NestedList(t *testing.T) {
+	blocks, err := parser.Parse([]byte("2. Préparer\n\n   - le pain\n   - le `fromage`\n\n3. Servir\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := &upperTranslator{}
+	result, err := translateWithEngine(context.Background(), tr, blocks)
+	if err != nil {
+		t.Fatalf("translateWithEngine failed: %v", err)
+	}
+
+	want := []string{"Préparer", "le pain", "le `fromage`", "Servir"}
+	if strings.Join(tr.texts, ",") != strings.Join(want, ",") {
+		t.Errorf("paragraphs should be translated one by one, got %q", tr.texts)
+	}
+	b := result[0]
+	wantRaw := "2. PRÉPARER\n\n   - LE PAIN\n   - LE `FROMAGE`\n\n3. SERVIR\n"
+	if b.Raw != wantRaw {
+		t.Errorf("translated list = %q, want %q", b.Raw, wantRaw)
+	}
+	if !strings.Contains(b.HTML, `<ol start="2">`) || !strings.Contains(b.HTML, "<ul>\n<li>LE PAIN</li>") {
+		t.Errorf("translated list should keep its start and nesting, got %q", b.HTML)
+	}
+}
+
+func TestTranslateWithEngine_HeadingAndQuote(t *testing.T) {
+	blocks, err := parser.Parse([]byte("## Le *bon* pain\n\n> Un **avis** :\n>\n> - le pain\n>\n> ```\n> code\n> ```\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := &upperTranslator{}
+	result, err := translateWithEngine(context.Background(), tr, blocks)
+	if err != nil {
+		t.Fatalf("translateWithEngine failed: %v", err)
+	}
+
+	want := []string{"Le *bon* pain", "Un **avis** :", "le pain"}
+	if strings.Join(tr.texts, ",") != strings.Join(want, ",") {
+		t.Errorf("heading and quote paragraphs should be sent with their markup, got %q", tr.texts)
+	}
+	if !strings.Contains(result[0].HTML, "<h2>LE <em>BON</em> PAIN</h2>") {
+		t.Errorf("translated heading should keep its markup, got %q", result[0].HTML)
+	}
+	wantRaw := "> UN **AVIS** :\n>\n> - LE PAIN\n>\n> ```\n> code\n> ```\n"
+	if result[1].Raw != wantRaw {
+		t.Errorf("translated quote = %q, want %q", result[1].Raw, wantRaw)
+	}
+}
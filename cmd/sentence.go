@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"html/template"
+	"regexp"
+	"strings"
+
+	"bilingual_pdf/internal/align"
+	"bilingual_pdf/internal/mask"
+	"bilingual_pdf/internal/parser"
+	"bilingual_pdf/internal/placeholder"
+	"bilingual_pdf/internal/renderer"
+	"bilingual_pdf/internal/sentence"
+)
+
+// granularities lists the accepted --granularity values: one row per block
+// of the document, or one row per sentence of paragraphs and list items.
+var granularities = []string{"block", "sentence"}
+
+var granularity string
+
+// itemPattern matches the marker of a list item at the start of the raw
+// markdown of a sentence.
+var itemPattern = regexp.MustCompile(`^ *(?:[-*+]|\d+[.)]) `)
+
+// A sentenceRow describes a row of the output in sentence granularity.
+type sentenceRow struct {
+	Block int  // index of the block the sentence comes from
+	First bool // first sentence of the block
+	Item  bool // sentence of a list item after its first sentence
+	Depth int  // nesting depth of the list item, 0 at the top level
+
+	// List is the list the sentence comes from, nil outside lists, and
+	// Segment the index in List.Texts() of the paragraph it belongs to.
+	List    *parser.List
+	Segment int
+}
+
+// splitSentences splits paragraphs and list items into one block per
+// sentence, to be translated one by one. The first sentence of a list item
+// keeps the item marker; the following ones are paragraphs. Other blocks,
+// and the code blocks and quotes of list items, are kept whole.
+func splitSentences(blocks []parser.Block, lang string) ([]parser.Block, []sentenceRow) {
+	var result []parser.Block
+	var rows []sentenceRow
+	for i, b := range blocks {
+		sentences, sRows := blockSentences(b, lang)
+		for k, s := range sentences {
+			row := sRows[k]
+			row.Block, row.First = i, k == 0
+			result = append(result, s)
+			rows = append(rows, row)
+		}
+	}
+	return result, rows
+}
+
+// alignSentences splits the rows of a translation read from a file into
+// sentences, source and translation alike, and aligns the sentences of each
+// row with align.Blocks.
+func alignSentences(blocks, translatedBlocks []parser.Block) ([]parser.Block, []parser.Block, []sentenceRow) {
+	var source, translated []parser.Block
+	var rows []sentenceRow
+	for i, b := range blocks {
+		var tb parser.Block
+		if i < len(translatedBlocks) {
+			tb = translatedBlocks[i]
+		}
+		ss, sRows := blockSentences(b, sourceLang)
+		ts, tRows := blockSentences(tb, targetLang)
+		pairs := align.Blocks(ss, ts)
+		if len(pairs) == 0 {
+			source, translated = append(source, b), append(translated, tb)
+			rows = append(rows, sentenceRow{Block: i, First: true})
+			continue
+		}
+		for k, p := range pairs {
+			row := sentenceRow{Block: i, First: k == 0}
+			var s, t parser.Block
+			if p.Source >= 0 {
+				s, row.Item, row.Depth = ss[p.Source], sRows[p.Source].Item, sRows[p.Source].Depth
+			}
+			if p.Target >= 0 {
+				t, row.Item = ts[p.Target], row.Item || tRows[p.Target].Item
+				if p.Source < 0 {
+					row.Depth = tRows[p.Target].Depth
+				}
+			}
+			source, translated = append(source, s), append(translated, t)
+			rows = append(rows, row)
+		}
+	}
+	return source, translated, rows
+}
+
+// blockSentences returns the sentences of a paragraph or list as blocks,
+// with their rows. Other blocks are returned whole, and an empty block, the
+// gap of an alignment, has no sentences.
+func blockSentences(b parser.Block, lang string) ([]parser.Block, []sentenceRow) {
+	if b.Raw == "" && b.HTML == "" {
+		return nil, nil
+	}
+	var sentences []parser.Block
+	var rows []sentenceRow
+	switch {
+	case b.Kind == parser.BlockParagraph:
+		for _, s := range splitMarkdown(b.Raw, lang) {
+			sentences = append(sentences, sentenceBlock(s, parser.BlockParagraph, b.Line))
+			rows = append(rows, sentenceRow{})
+		}
+	case b.Kind == parser.BlockList && b.List != nil:
+		segment := 0
+		listSentences(b.List, 0, &segment, lang, func(s parser.Block, row sentenceRow) {
+			s.Line, row.List = b.Line, b.List
+			sentences = append(sentences, s)
+			rows = append(rows, row)
+		})
+	}
+	if len(sentences) == 0 {
+		return []parser.Block{b}, []sentenceRow{{}}
+	}
+	return sentences, rows
+}
+
+// listSentences calls add with each sentence of a list and its row, in
+// reading order. The first block of an item keeps the item marker, so that
+// ordered items keep their number, and nested items are rows of their own
+// at their depth. Headings, quotes and code blocks are single rows; code
+// blocks are not translated. segment counts the paragraphs of the list, as
+// List.Texts() does.
+func listSentences(l *parser.List, depth int, segment *int, lang string, add func(parser.Block, sentenceRow)) {
+	for i, item := range l.Items {
+		for j, nb := range item.Blocks {
+			row := sentenceRow{Item: j > 0, Depth: depth, Segment: *segment}
+			switch {
+			case nb.List != nil:
+				listSentences(nb.List, depth+1, segment, lang, add)
+			case nb.Quote != nil:
+				add(sentenceBlock(itemMarkdown(l, i, j, nb), itemKind(j, parser.BlockBlockquote), 0), row)
+				*segment += len(nb.Quote.Texts())
+			case nb.Verbatim != "":
+				s := sentenceBlock(itemMarkdown(l, i, j, nb), itemKind(j, parser.BlockCodeBlock), 0)
+				s.NoTranslate = true
+				add(s, row)
+			case nb.Prefix != "":
+				add(sentenceBlock(itemMarkdown(l, i, j, nb), itemKind(j, parser.BlockHeading), 0), row)
+				*segment++
+			default:
+				for k, md := range splitMarkdown(nb.Text, lang) {
+					row.Item = j > 0 || k > 0
+					if k == 0 {
+						md = itemMarkdown(l, i, j, parser.NestedBlock{Text: md})
+					}
+					add(sentenceBlock(md, itemKind(j+k, parser.BlockParagraph), 0), row)
+				}
+				*segment++
+			}
+		}
+	}
+}
+
+// itemMarkdown returns the markdown of block j of item i of a list: as an
+// item of its own, with the marker and number of the item, for the first
+// block of the item, or the block alone.
+func itemMarkdown(l *parser.List, i, j int, nb parser.NestedBlock) string {
+	if j > 0 {
+		switch {
+		case nb.Quote != nil:
+			return nb.Quote.Markdown()
+		case nb.Verbatim != "":
+			return nb.Verbatim
+		}
+		return nb.Prefix + nb.Text
+	}
+	item := *l
+	item.Items = []parser.ListItem{{Blocks: []parser.NestedBlock{nb}}}
+	if item.Ordered {
+		item.Start += i
+	}
+	return item.Markdown()
+}
+
+// itemKind returns the kind of a row of an item: a list for the first, which
+// carries the item marker, kind for the others.
+func itemKind(n int, kind parser.BlockKind) parser.BlockKind {
+	if n == 0 {
+		return parser.BlockList
+	}
+	return kind
+}
+
+// splitMarkdown splits inline markdown into sentences. Code spans, URLs,
+// link destinations and HTML tags are masked first, so that their periods
+// do not end a sentence.
+func splitMarkdown(md, lang string) []string {
+	set := placeholder.NewSet(mask.Prefix)
+	masked := mask.Markdown(strings.ReplaceAll(md, "\n", " "), set)
+	var sentences []string
+	for _, s := range sentence.Split(masked, lang) {
+		restored, _ := set.Restore(s)
+		sentences = append(sentences, restored)
+	}
+	return sentences
+}
+
+// sentenceBlock parses a sentence into a block of the given kind. A
+// sentence that reads as another kind of block, such as "1984. A year."
+// read as a list, is kept as a paragraph of text.
+func sentenceBlock(md string, kind parser.BlockKind, line int) parser.Block {
+	b := parser.Block{
+		Kind: kind,
+		Raw:  md,
+		Text: md,
+		HTML: "<p>" + template.HTMLEscapeString(md) + "</p>\n",
+	}
+	if parsed, err := parser.Parse([]byte(md)); err == nil && len(parsed) == 1 && parsed[0].Kind == kind {
+		b = parsed[0]
+	}
+	b.Line = line
+	return b
+}
+
+// joinSentences joins the sentence rows of each block back into one block,
+// for the saved translation. A list is rebuilt from the structure of the
+// source list, with the sentences of each of its paragraphs joined.
+func joinSentences(rows []sentenceRow, blocks []parser.Block) []parser.Block {
+	var result []parser.Block
+	for i := 0; i < len(blocks); {
+		end := i + 1
+		for end < len(blocks) && rows[end].Block == rows[i].Block {
+			end++
+		}
+		if rows[i].List != nil {
+			result = append(result, joinList(rows[i:end], blocks[i:end]))
+		} else {
+			result = append(result, joinParagraph(blocks[i:end]))
+		}
+		i = end
+	}
+	return result
+}
+
+// joinList rebuilds a list from the rows of its sentences.
+func joinList(rows []sentenceRow, blocks []parser.Block) parser.Block {
+	list := rows[0].List
+	texts := make([]string, len(list.Texts()))
+	for k, b := range blocks {
+		for n, t := range sentenceTexts(b) {
+			if idx := rows[k].Segment + n; idx < len(texts) {
+				texts[idx] = joinNonEmpty(texts[idx], " ", t)
+			}
+		}
+	}
+	joined := list.WithTexts(texts)
+	return parser.Block{
+		Kind: parser.BlockList,
+		Raw:  joined.Markdown(),
+		Text: strings.Join(texts, "\n"),
+		List: joined,
+		Line: blocks[0].Line,
+	}
+}
+
+// sentenceTexts returns the inline markdown of the paragraphs of a row of
+// a list: none for a code block.
+func sentenceTexts(b parser.Block) []string {
+	switch {
+	case b.NoTranslate:
+		return nil
+	case b.List != nil:
+		return b.List.Texts()
+	case b.Quote != nil:
+		return b.Quote.Texts()
+	case b.Kind == parser.BlockList:
+		raw := strings.TrimRight(b.Raw, "\n")
+		return []string{raw[len(itemPattern.FindString(raw)):]}
+	}
+	if t := segmentText(b); t != "" {
+		return []string{strings.TrimRight(t, "\n")}
+	}
+	return nil
+}
+
+// joinParagraph joins the sentences of a paragraph, or returns any other
+// block as it is.
+func joinParagraph(blocks []parser.Block) parser.Block {
+	result := blocks[0]
+	for _, b := range blocks[1:] {
+		if b.Raw == "" && b.Text == "" {
+			continue
+		}
+		if result.Raw == "" && result.Text == "" {
+			result = b
+			continue
+		}
+		result.Raw = joinNonEmpty(strings.TrimRight(result.Raw, "\n"), " ", strings.TrimRight(b.Raw, "\n"))
+		result.Text = joinNonEmpty(result.Text, " ", b.Text)
+	}
+	return result
+}
+
+func joinNonEmpty(a, sep, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + sep + b
+}
+
+// decorateSentences marks the rows followed by a sentence of the same block,
+// and indents the sentences that continue a list item and those of nested
+// items.
+func decorateSentences(pairs []renderer.BlockPair, rows []sentenceRow) {
+	for i := range pairs {
+		if i >= len(rows) {
+			break
+		}
+		pairs[i].Continues = i+1 < len(rows) && !rows[i+1].First
+		if rows[i].Item {
+			pairs[i].Source = indentItem(pairs[i].Source, "item-continued")
+			pairs[i].Target = indentItem(pairs[i].Target, "item-continued")
+		}
+		for d := 0; d < rows[i].Depth; d++ {
+			pairs[i].Source = indentItem(pairs[i].Source, "item-nested")
+			pairs[i].Target = indentItem(pairs[i].Target, "item-nested")
+		}
+	}
+}
+
+func indentItem(h template.HTML, class string) template.HTML {
+	if h == "" {
+		return h
+	}
+	return template.HTML(`<div class="`+class+`">`) + h + "</div>\n"
+}
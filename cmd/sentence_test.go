@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"bilingual_pdf/internal/parser"
+	"bilingual_pdf/internal/renderer"
+)
+
+func TestSplitSentences(t *testing.T) {
+	md := "# Titre\n\nVoir `a.b` sur https://example.com/x.html. Puis M. Dupont arrive !\n\n" +
+		"- Pain frais. Très bon.\n- Vin\n\n1. Un. Deux.\n2. Trois.\n"
+	blocks, _ := parser.Parse([]byte(md))
+	got, rows := splitSentences(blocks, "fr")
+
+	want := []struct {
+		kind parser.BlockKind
+		raw  string
+		row  sentenceRow
+	}{
+		{parser.BlockHeading, "# Titre", sentenceRow{Block: 0, First: true}},
+		{parser.BlockParagraph, "Voir `a.b` sur https://example.com/x.html.", sentenceRow{Block: 1, First: true}},
+		{parser.BlockParagraph, "Puis M. Dupont arrive !", sentenceRow{Block: 1}},
+		{parser.BlockList, "- Pain frais.", sentenceRow{Block: 2, First: true}},
+		{parser.BlockParagraph, "Très bon.", sentenceRow{Block: 2, Item: true}},
+		{parser.BlockList, "- Vin", sentenceRow{Block: 2}},
+		{parser.BlockList, "1. Un.", sentenceRow{Block: 3, First: true}},
+		{parser.BlockParagraph, "Deux.", sentenceRow{Block: 3, Item: true}},
+		{parser.BlockList, "2. Trois.", sentenceRow{Block: 3}},
+	}
+	if len(got) != len(want) || len(rows) != len(want) {
+		t.Fatalf("got %d blocks and %d rows, want %d", len(got), len(rows), len(want))
+	}
+	for i, w := range want {
+		row := sentenceRow{Block: rows[i].Block, First: rows[i].First, Item: rows[i].Item}
+		if got[i].Kind != w.kind || strings.TrimRight(got[i].Raw, "\n") != w.raw || row != w.row {
+			t.Errorf("row %d = %v %q %+v, want %v %q %+v", i, got[i].Kind, got[i].Raw, row, w.kind, w.raw, w.row)
+		}
+	}
+	if !strings.Contains(got[8].HTML, `<ol start="2">`) {
+		t.Errorf("an ordered item should keep its number, got %q", got[8].HTML)
+	}
+	if got[2].Line != 3 {
+		t.Errorf("sentences should keep the line of their block, got %d", got[2].Line)
+	}
+}
+
+func TestAlignSentences(t *testing.T) {
+	oldSource, oldTarget := sourceLang, targetLang
+	defer func() { sourceLang, targetLang = oldSource, oldTarget }()
+	sourceLang, targetLang = "fr", "es"
+
+	source, _ := parser.Parse([]byte("# Titre\n\nUne phrase. Une autre phrase assez longue.\n"))
+	target, _ := parser.Parse([]byte("# Título\n\nUna frase. Otra frase bastante larga. Y una más.\n"))
+	s, tr, rows := alignSentences(source, target)
+
+	if len(s) != 4 || len(tr) != 4 || len(rows) != 4 {
+		t.Fatalf("got %d, %d and %d rows, want 4", len(s), len(tr), len(rows))
+	}
+	if s[3].Raw != "" || tr[3].Raw != "Y una más." {
+		t.Errorf("the extra sentence should get a row of its own, got %q and %q", s[3].Raw, tr[3].Raw)
+	}
+	if !rows[1].First || rows[2].First || rows[3].Block != 1 {
+		t.Errorf("unexpected rows %+v", rows)
+	}
+}
+
+func TestJoinSentences(t *testing.T) {
+	blocks, _ := parser.Parse([]byte("Un. Deux.\n\n- Pain. Bon.\n- Vin\n"))
+	split, rows := splitSentences(blocks, "fr")
+	joined := joinSentences(rows, split)
+
+	if len(joined) != 2 {
+		t.Fatalf("got %d blocks, want 2", len(joined))
+	}
+	if joined[0].Raw != "Un. Deux." {
+		t.Errorf("paragraph = %q", joined[0].Raw)
+	}
+	if joined[1].Raw != "- Pain. Bon.\n- Vin\n" {
+		t.Errorf("list = %q", joined[1].Raw)
+	}
+}
+
+func TestSplitSentences_NestedList(t *testing.T) {
+	md := "- Point. Suite.\n\n  Second paragraphe.\n\n  - Sous point.\n\n    ```go\n    x := 1. Foo\n    ```\n\n- Fin.\n"
+	blocks, _ := parser.Parse([]byte(md))
+	got, rows := splitSentences(blocks, "fr")
+
+	want := []struct {
+		kind  parser.BlockKind
+		raw   string
+		item  bool
+		depth int
+	}{
+		{parser.BlockList, "- Point.", false, 0},
+		{parser.BlockParagraph, "Suite.", true, 0},
+		{parser.BlockParagraph, "Second paragraphe.", true, 0},
+		{parser.BlockList, "- Sous point.", false, 1},
+		{parser.BlockCodeBlock, "```go\nx := 1. Foo\n```", true, 1},
+		{parser.BlockList, "- Fin.", false, 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Kind != w.kind || strings.TrimRight(got[i].Raw, "\n") != w.raw || rows[i].Item != w.item || rows[i].Depth != w.depth {
+			t.Errorf("row %d = %v %q %+v, want %v %q item %v depth %d", i, got[i].Kind, got[i].Raw, rows[i], w.kind, w.raw, w.item, w.depth)
+		}
+	}
+	if !got[4].NoTranslate || !strings.Contains(got[4].HTML, "<pre><code") {
+		t.Errorf("a nested code block should be an untranslated row, got %+v", got[4])
+	}
+
+	// Translated sentence by sentence, the list is saved with its
+	// structure.
+	translated, err := translateWithEngine(context.Background(), &upperTranslator{}, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	joined := joinSentences(rows, translated)
+	wantRaw := "- POINT. SUITE.\n\n  SECOND PARAGRAPHE.\n\n  - SOUS POINT.\n\n    ```go\n    x := 1. Foo\n    ```\n\n- FIN.\n"
+	if len(joined) != 1 || joined[0].Raw != wantRaw {
+		t.Errorf("joined translation = %q, want %q", joined[0].Raw, wantRaw)
+	}
+	if source := joinSentences(rows, got); source[0].Raw != md {
+		t.Errorf("joined source = %q, want %q", source[0].Raw, md)
+	}
+
+	pairs := buildPairs(got, translated)
+	decorateSentences(pairs, rows)
+	if !strings.Contains(string(pairs[4].Target), `<div class="item-nested"><div class="item-continued">`) {
+		t.Errorf("the code block of a nested item should be indented twice, got %q", pairs[4].Target)
+	}
+}
+
+func TestDecorateSentences(t *testing.T) {
+	pairs := []renderer.BlockPair{{Source: "<p>a</p>"}, {Source: "<p>b</p>", Target: "<p>B</p>"}, {Source: "<p>c</p>"}}
+	rows := []sentenceRow{{Block: 0, First: true}, {Block: 0, Item: true}, {Block: 1, First: true}}
+	decorateSentences(pairs, rows)
+
+	if !pairs[0].Continues || pairs[1].Continues || pairs[2].Continues {
+		t.Errorf("unexpected continued rows %+v", pairs)
+	}
+	if !strings.Contains(string(pairs[1].Source), "item-continued") || !strings.Contains(string(pairs[1].Target), "item-continued") {
+		t.Errorf("item continuation should be indented, got %+v", pairs[1])
+	}
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bilingual_pdf/internal/naming"
+	"bilingual_pdf/internal/parser"
+	"bilingual_pdf/internal/tmx"
+)
+
+var (
+	tmFiles []string
+	saveTMX bool
+)
+
+// validateTMXFiles checks that the --tm files exist and have a TMX extension.
+func validateTMXFiles() error {
+	for _, path := range tmFiles {
+		if ext := filepath.Ext(path); strings.ToLower(ext) != ".tmx" {
+			return fmt.Errorf("--tm file must have .tmx extension, got %q", ext)
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("translation memory not found: %s", path)
+		}
+	}
+	return nil
+}
+
+// loadMemory reads the source → target pairs of the --tm files into one
+// translation memory; a segment found in several files takes the
+// translation of the last one.
+func loadMemory() (*tmx.Memory, error) {
+	mem := tmx.NewMemory()
+	for _, path := range tmFiles {
+		doc, err := tmx.Load(path, sourceLang, targetLang)
+		if err != nil {
+			return nil, err
+		}
+		if len(doc.Pairs) == 0 {
+			fmt.Fprintf(os.Stderr, "Warning: %s has no %s → %s translation units\n", path, sourceLang, targetLang)
+		}
+		mem.Add(doc.Pairs)
+	}
+	return mem, nil
+}
+
+// tmxPairs returns the distinct pairs of a source block and its translation,
+// as the text sent to a translation engine, skipping code blocks, thematic
+// breaks and untranslated blocks. Tables give a pair per cell, and lists
+// and quotes a pair per paragraph.
+func tmxPairs(blocks, translatedBlocks []parser.Block) []tmx.Pair {
+	var pairs []tmx.Pair
+	seen := map[tmx.Pair]bool{}
+	for i, b := range blocks {
+		if i >= len(translatedBlocks) || b.Kind == parser.BlockThematicBreak {
+			continue
+		}
+		t := translatedBlocks[i]
+		sources := blockSegments(b)
+		// The text of the translation is chosen by the kind of the
+		// source block, as it was sent to the engine.
+		targets := blockSegments(parser.Block{Kind: b.Kind, Level: t.Level, Raw: t.Raw, Text: t.Text, Table: t.Table, List: t.List, Quote: t.Quote})
+		if len(sources) != len(targets) {
+			continue
+		}
+		for k := range sources {
+			p := tmx.Pair{Source: sources[k], Target: targets[k]}
+			if strings.TrimSpace(p.Source) == "" || strings.TrimSpace(p.Target) == "" || seen[p] {
+				continue
+			}
+			seen[p] = true
+			pairs = append(pairs, p)
+		}
+	}
+	return pairs
+}
+
+// maybeSaveTMX saves the aligned blocks as a TMX translation memory next to
+// the output.
+func maybeSaveTMX(inputFile string, blocks, translatedBlocks []parser.Block) error {
+	if !saveTMX {
+		return nil
+	}
+	doc := &tmx.Document{
+		SourceLang:  sourceLang,
+		TargetLang:  targetLang,
+		ToolVersion: Version,
+		Created:     time.Now(),
+		Pairs:       tmxPairs(blocks, translatedBlocks),
+	}
+	path := naming.TMXName(inputFile, sourceLang, targetLang, outputFile)
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("saving TMX: %w", err)
+	}
+	if err := doc.Write(f); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("saving TMX: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("saving TMX: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Saved TMX: %s (%d translation units)\n", path, len(doc.Pairs))
+	return nil
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bilingual_pdf/internal/parser"
+	"bilingual_pdf/internal/tmx"
+)
+
+func TestTMXPairs(t *testing.T) {
+	blocks, _ := parser.Parse([]byte("# Titre\n\nUn *mot*.\n\n```\ncode\n```\n\n---\n\nUn *mot*.\n\nVide.\n"))
+	translated, _ := parser.Parse([]byte("# Título\n\nUna *palabra*.\n\n```\ncode\n```\n\n---\n\nUna *palabra*.\n"))
+
+	got := tmxPairs(blocks, translated)
+	want := []tmx.Pair{
+		{Source: "Titre", Target: "Título"},
+		{Source: "Un *mot*.", Target: "Una *palabra*."},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got pairs %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("pair %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPipeline_SaveTMXFromTranslationFile(t *testing.T) {
+	usePseudoEngine(t)
+	defer func() { saveTMX = false }()
+	input := copySample(t, "sample.fr.md")
+	translationFile = filepath.Join("..", "testdata", "sample.es.md")
+	saveTMX = true
+
+	if _, err := buildHTML(context.Background(), input); err != nil {
+		t.Fatalf("buildHTML failed: %v", err)
+	}
+
+	doc, err := tmx.Load(filepath.Join(filepath.Dir(input), "sample.fr.es.tmx"), "fr", "es")
+	if err != nil {
+		t.Fatalf("expected the TMX file to be saved: %v", err)
+	}
+	if len(doc.Pairs) == 0 || doc.Pairs[0] != (tmx.Pair{Source: "Bonjour le monde", Target: "Hola Mundo"}) {
+		t.Errorf("unexpected pairs %+v", doc.Pairs)
+	}
+	for _, p := range doc.Pairs {
+		if strings.Contains(p.Source, "def bonjour") {
+			t.Errorf("code blocks should not be exported: %+v", p)
+		}
+	}
+}
+
+func TestPipeline_TranslationMemory(t *testing.T) {
+	usePseudoEngine(t)
+	defer func() { tmFiles = nil }()
+	input := copySample(t, "sample.fr.md")
+
+	path := filepath.Join(t.TempDir(), "memory.tmx")
+	doc := &tmx.Document{SourceLang: "fr", TargetLang: "es", Pairs: []tmx.Pair{
+		{Source: "Bonjour le monde", Target: "Hola mundo"},
+	}}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Write(f); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	tmFiles = []string{path}
+
+	html, err := buildHTML(context.Background(), input)
+	if err != nil {
+		t.Fatalf("buildHTML failed: %v", err)
+	}
+	if !strings.Contains(html, "<h1>Hola mundo</h1>") {
+		t.Errorf("the heading should come from the translation memory")
+	}
+	if strings.Contains(html, "Ɓöñĵöûŕ ļé ɱöñðé") {
+		t.Errorf("the matched heading should not reach the engine")
+	}
+	if !strings.Contains(html, "[Ļéš šáíšöñš") {
+		t.Errorf("other blocks should be translated by the engine")
+	}
+}
+
+func TestPipeline_TranslationMemoryWithMarkedPhrase(t *testing.T) {
+	usePseudoEngine(t)
+	defer func() { tmFiles = nil }()
+	dir := t.TempDir()
+	input := filepath.Join(dir, "doc.fr.md")
+	if err := os.WriteFile(input, []byte("Bienvenue chez [Acme]{.notranslate}.\n\nAu revoir.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "memory.tmx")
+	doc := &tmx.Document{SourceLang: "fr", TargetLang: "es", Pairs: []tmx.Pair{
+		{Source: "Bienvenue chez [Acme]{.notranslate}.", Target: "Bienvenidos a Acme."},
+	}}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Write(f); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	tmFiles = []string{path}
+
+	if _, err := buildHTML(context.Background(), input); err != nil {
+		t.Fatalf("buildHTML failed: %v", err)
+	}
+	translation, err := os.ReadFile(filepath.Join(dir, "doc.es.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(translation), "Bienvenidos a Acme.\n") {
+		t.Errorf("the block with a marked phrase should come from the translation memory, got %q", translation)
+	}
+	if strings.Contains(string(translation), "Au revoir.") {
+		t.Errorf("other blocks should be translated by the engine, got %q", translation)
+	}
+}
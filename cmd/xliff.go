@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bilingual_pdf/internal/languages"
+	"bilingual_pdf/internal/naming"
+	"bilingual_pdf/internal/parser"
+	"bilingual_pdf/internal/xliff"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	translationXLIFF string
+	xliffOutput      string
+)
+
+var exportXLIFFCmd = &cobra.Command{
+	Use:   "export-xliff input.md",
+	Short: "Export the blocks of a markdown file as XLIFF 2.0 for CAT tools",
+	Long: `Writes every block of the document as an XLIFF 2.0 unit, with the block
+kind and heading level as metadata. Code blocks and thematic breaks are
+marked translate="no", and inline markdown syntax (emphasis, links, code
+spans, HTML tags) becomes placeholders. Read the translated file back with
+--translation-xliff.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExportXLIFF,
+}
+
+func init() {
+	exportXLIFFCmd.Flags().StringVarP(&sourceLang, "source", "s", "fr", "source language code, or auto to detect it")
+	exportXLIFFCmd.Flags().StringVarP(&targetLang, "target", "t", "es", "target language code")
+	exportXLIFFCmd.Flags().StringVarP(&xliffOutput, "output", "o", "", "output XLIFF filename")
+	rootCmd.AddCommand(exportXLIFFCmd)
+}
+
+func runExportXLIFF(cmd *cobra.Command, args []string) error {
+	inputFile := args[0]
+	if ext := filepath.Ext(inputFile); strings.ToLower(ext) != ".md" {
+		return fmt.Errorf("input file must have .md extension, got %q", ext)
+	}
+	if err := loadFrontMatter(cmd, inputFile); err != nil {
+		return err
+	}
+	if xliffOutput != "" {
+		if err := validateXLIFFExt("--output", xliffOutput); err != nil {
+			return err
+		}
+	}
+	if sourceLang != languages.Auto {
+		if err := languages.Validate(sourceLang); err != nil {
+			return fmt.Errorf("invalid source language: %w", err)
+		}
+	}
+	if err := languages.Validate(targetLang); err != nil {
+		return fmt.Errorf("invalid target language: %w", err)
+	}
+
+	blocks, err := readAndParse(inputFile)
+	if err != nil {
+		return err
+	}
+	if sourceLang == languages.Auto {
+		// exports need no engine, so the language is detected offline
+		if err := detectSource(cmd.Context(), blocks, ""); err != nil {
+			return err
+		}
+	}
+
+	doc := xliffDocument(inputFile, blocks)
+	path := xliffOutput
+	if path == "" {
+		path = naming.XLIFFName(inputFile, sourceLang, targetLang, "")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("saving XLIFF: %w", err)
+	}
+	if err := doc.Write(f); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("saving XLIFF: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("saving XLIFF: %w", err)
+	}
+
+	toTranslate := 0
+	for _, u := range doc.Units {
+		if u.Translate {
+			toTranslate++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Saved XLIFF: %s (%d units, %d to translate)\n", path, len(doc.Units), toTranslate)
+	return nil
+}
+
+// validateXLIFFExt checks that path has an XLIFF extension.
+func validateXLIFFExt(flag, path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xlf", ".xliff":
+		return nil
+	}
+	return fmt.Errorf("%s file must have .xlf or .xliff extension, got %q", flag, filepath.Ext(path))
+}
+
+// xliffUnitID returns the id of the unit of block i.
+func xliffUnitID(i int) string {
+	return fmt.Sprintf("b%d", i)
+}
+
+// xliffDocument returns the units of the blocks: the text that would be
+// sent to a translation engine, and the source of code blocks, thematic
+// breaks and marked blocks, which are not to be translated. A block with a
+// fixed translation is given as that translation.
+func xliffDocument(inputFile string, blocks []parser.Block) *xliff.Document {
+	doc := &xliff.Document{
+		SourceLang: sourceLang,
+		TargetLang: targetLang,
+		Original:   filepath.Base(inputFile),
+	}
+	for i, b := range blocks {
+		u := xliff.Unit{
+			ID:        xliffUnitID(i),
+			Kind:      b.Kind.String(),
+			Level:     b.Level,
+			Translate: true,
+			Source:    segmentText(b),
+		}
+		switch {
+		case b.Kind == parser.BlockCodeBlock, b.NoTranslate:
+			u.Translate, u.Source = false, b.Raw
+		case b.Kind == parser.BlockThematicBreak:
+			u.Translate = false
+		case b.Translation != "":
+			u.Translate, u.Source = false, b.Translation
+		}
+		doc.Units = append(doc.Units, u)
+	}
+	return doc
+}
+
+// translateFromXLIFF reads the translations of the blocks from an XLIFF
+// file, matching units to blocks with a unitMatcher.
+func translateFromXLIFF(path string, blocks []parser.Block) ([]parser.Block, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading XLIFF file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	doc, err := xliff.Read(f)
+	if err != nil {
+		return nil, err
+	}
+	if doc.SourceLang != "" && doc.SourceLang != sourceLang {
+		fmt.Fprintf(os.Stderr, "Warning: %s is from %s, not %s\n", path, doc.SourceLang, sourceLang)
+	}
+	if doc.TargetLang != "" && doc.TargetLang != targetLang {
+		fmt.Fprintf(os.Stderr, "Warning: %s is a translation to %s, not %s\n", path, doc.TargetLang, targetLang)
+	}
+
+	ids := make([]string, len(doc.Units))
+	sources := make([]string, len(doc.Units))
+	for j, u := range doc.Units {
+		ids[j], sources[j] = u.ID, u.Source
+	}
+	m := newUnitMatcher(ids, sources)
+
+	texts := make([]string, len(blocks))
+	var changed, untranslated []string
+	for i, b := range blocks {
+		source := segmentText(b)
+		if source == "" {
+			continue
+		}
+		j := m.match(xliffUnitID(i), source)
+		if j < 0 {
+			changed = append(changed, fmt.Sprint(i))
+			continue
+		}
+		u := doc.Units[j]
+		switch {
+		case !u.Translate:
+			texts[i] = source
+		case u.Target == "":
+			untranslated = append(untranslated, fmt.Sprint(i))
+		default:
+			texts[i] = u.Target
+		}
+		if len(u.Lost) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: block %d: the translation lost %d placeholder(s): %s\n",
+				i, len(u.Lost), strings.Join(quoteAll(u.Lost), ", "))
+		}
+	}
+
+	if len(changed) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d block(s) changed since the XLIFF export and are left untranslated: %s\n",
+			len(changed), strings.Join(changed, ", "))
+	}
+	if len(untranslated) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d block(s) have no translation in %s: %s\n",
+			len(untranslated), path, strings.Join(untranslated, ", "))
+	}
+	return buildTranslatedBlocks(blocks, texts), nil
+}
+
+// unitMatcher finds the exported unit of a block: the unit with the id of
+// the block, as long as its source is still the text of the block, otherwise
+// the first unused unit with the same source. Edits of the document after
+// the export then only leave the changed blocks unmatched.
+type unitMatcher struct {
+	byID     map[string]int
+	bySource map[string][]int
+	sources  []string
+	used     []bool
+}
+
+// newUnitMatcher returns a matcher for units with the given ids and sources.
+func newUnitMatcher(ids, sources []string) *unitMatcher {
+	m := &unitMatcher{
+		byID:     map[string]int{},
+		bySource: map[string][]int{},
+		sources:  sources,
+		used:     make([]bool, len(sources)),
+	}
+	for j, source := range sources {
+		m.byID[ids[j]] = j
+		m.bySource[source] = append(m.bySource[source], j)
+	}
+	return m
+}
+
+// match returns the index of the unit of the block, or -1, and marks the
+// unit as used.
+func (m *unitMatcher) match(id, source string) int {
+	j, ok := m.byID[id]
+	if !ok || m.used[j] || m.sources[j] != source {
+		j = -1
+		for _, k := range m.bySource[source] {
+			if !m.used[k] {
+				j = k
+				break
+			}
+		}
+	}
+	if j >= 0 {
+		m.used[j] = true
+	}
+	return j
+}
+
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return quoted
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bilingual_pdf/internal/parser"
+)
+
+// writeXLIFF exports blocks with the targets given by translate.
+func writeXLIFF(t *testing.T, blocks []parser.Block, translate func(string) string) string {
+	t.Helper()
+	doc := xliffDocument("doc.fr.md", blocks)
+	for i, u := range doc.Units {
+		if u.Translate {
+			doc.Units[i].Target = translate(u.Source)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "doc.fr.es.xlf")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	if err := doc.Write(f); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTranslateFromXLIFF(t *testing.T) {
+	oldSource, oldTarget := sourceLang, targetLang
+	defer func() { sourceLang, targetLang = oldSource, oldTarget }()
+	sourceLang, targetLang = "fr", "es"
+
+	blocks, err := parser.Parse([]byte("# Le titre\n\nLisez [le guide](https://example.com).\n\n```\ncode\n```\n\n- un\n- deux\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeXLIFF(t, blocks, func(s string) string {
+		r := strings.NewReplacer("Le titre", "El título", "Lisez", "Lea", "le guide", "la guía", "un", "uno", "deux", "dos")
+		return r.Replace(s)
+	})
+
+	got, err := translateFromXLIFF(path, blocks)
+	if err != nil {
+		t.Fatalf("translateFromXLIFF failed: %v", err)
+	}
+	if len(got) != len(blocks) {
+		t.Fatalf("expected %d blocks, got %d", len(blocks), len(got))
+	}
+	if got[0].Kind != parser.BlockHeading || got[0].Text != "El título" {
+		t.Errorf("unexpected heading %+v", got[0])
+	}
+	if !strings.Contains(got[1].HTML, `<a href="https://example.com">la guía</a>`) {
+		t.Errorf("link should survive the round trip, got %q", got[1].HTML)
+	}
+	if got[2].Raw != blocks[2].Raw {
+		t.Errorf("code block should be kept, got %q", got[2].Raw)
+	}
+	if got[3].Raw != "- uno\n- dos\n" {
+		t.Errorf("unexpected list %q", got[3].Raw)
+	}
+}
+
+func TestTranslateFromXLIFF_EditedDocument(t *testing.T) {
+	oldSource, oldTarget := sourceLang, targetLang
+	defer func() { sourceLang, targetLang = oldSource, oldTarget }()
+	sourceLang, targetLang = "fr", "es"
+
+	exported, _ := parser.Parse([]byte("Premier.\n\nSecond.\n"))
+	path := writeXLIFF(t, exported, func(s string) string {
+		return map[string]string{"Premier.": "Primero.", "Second.": "Segundo."}[s]
+	})
+
+	// A block was inserted at the top after the export.
+	edited, _ := parser.Parse([]byte("Nouveau.\n\nPremier.\n\nSecond.\n"))
+	got, err := translateFromXLIFF(path, edited)
+	if err != nil {
+		t.Fatalf("translateFromXLIFF failed: %v", err)
+	}
+	if got[0].Text != "" || got[1].Text != "Primero." || got[2].Text != "Segundo." {
+		t.Errorf("units should follow their source text, got %q, %q, %q", got[0].Text, got[1].Text, got[2].Text)
+	}
+}
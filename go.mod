@@ -3,15 +3,19 @@ module bilingual_pdf
 go 1.23.2
 
 require (
-	github.com/Conight/go-googletrans v0.2.4 // indirect
-	github.com/go-rod/rod v0.116.2 // indirect
+	github.com/Conight/go-googletrans v0.2.4
+	github.com/go-rod/rod v0.116.2
+	github.com/spf13/cobra v1.10.2
+	github.com/ysmood/gson v0.7.3
+	github.com/yuin/goldmark v1.7.16
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/cobra v1.10.2 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
 	github.com/ysmood/fetchup v0.2.3 // indirect
 	github.com/ysmood/goob v0.4.0 // indirect
 	github.com/ysmood/got v0.40.0 // indirect
-	github.com/ysmood/gson v0.7.3 // indirect
 	github.com/ysmood/leakless v0.9.0 // indirect
-	github.com/yuin/goldmark v1.7.16 // indirect
 )
@@ -0,0 +1,169 @@
+// Package align pairs the blocks of a document with the blocks of its
+// translation when their counts or structures differ, so that one extra or
+// missing block does not shift every following row.
+package align
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf8"
+
+	"bilingual_pdf/internal/parser"
+)
+
+// Costs of the alignment. Pairing blocks of different kinds costs more than
+// a gap, so an extra block is left unpaired rather than shifting the blocks
+// after it, but less than leaving both blocks unpaired.
+const (
+	gapCost      = 1.0 // a block without counterpart
+	endGapCost   = 0.5 // a source block after the last translated block, as in a partial translation
+	kindCost     = 1.4 // paired blocks of different kinds
+	levelCost    = 0.6 // paired headings of different levels
+	codeCost     = 0.8 // paired code blocks that differ
+	lengthWeight = 0.9 // paired blocks of very different lengths
+)
+
+// Pair is a row of an alignment: the index of a source block and the index
+// of its translation, -1 for a gap.
+type Pair struct {
+	Source int
+	Target int
+}
+
+// Blocks aligns the source blocks with the translated blocks by dynamic
+// programming, minimizing the cost of gaps and of mismatched pairs (block
+// kind, heading level, code block contents and length ratio). The pairs keep
+// the order of both sides.
+func Blocks(source, target []parser.Block) []Pair {
+	n, m := len(source), len(target)
+
+	// cost[i][j] is the cost of aligning source[:i] with target[:j].
+	cost := make([][]float64, n+1)
+	for i := range cost {
+		cost[i] = make([]float64, m+1)
+	}
+	// Source blocks left once the translation has run out are cheaper,
+	// so that a partial translation is paired with the start of the source.
+	sourceGap := func(j int) float64 {
+		if j == m {
+			return endGapCost
+		}
+		return gapCost
+	}
+	for i := 1; i <= n; i++ {
+		cost[i][0] = cost[i-1][0] + sourceGap(0)
+	}
+	for j := 1; j <= m; j++ {
+		cost[0][j] = float64(j) * gapCost
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			cost[i][j] = min(
+				cost[i-1][j-1]+pairCost(source[i-1], target[j-1]),
+				cost[i-1][j]+sourceGap(j),
+				cost[i][j-1]+gapCost,
+			)
+		}
+	}
+
+	// Walk back from the end, preferring pairs over gaps on ties.
+	var pairs []Pair
+	i, j := n, m
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && same(cost[i][j], cost[i-1][j-1]+pairCost(source[i-1], target[j-1])):
+			i, j = i-1, j-1
+			pairs = append(pairs, Pair{Source: i, Target: j})
+		case i > 0 && (j == 0 || same(cost[i][j], cost[i-1][j]+sourceGap(j))):
+			i--
+			pairs = append(pairs, Pair{Source: i, Target: -1})
+		default:
+			j--
+			pairs = append(pairs, Pair{Source: -1, Target: j})
+		}
+	}
+	for l, r := 0, len(pairs)-1; l < r; l, r = l+1, r-1 {
+		pairs[l], pairs[r] = pairs[r], pairs[l]
+	}
+	return pairs
+}
+
+func same(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// pairCost returns the cost of pairing a source block with a translated
+// block: 0 for blocks of the same kind and length.
+func pairCost(s, t parser.Block) float64 {
+	if s.Kind != t.Kind {
+		return kindCost
+	}
+	c := 0.0
+	switch s.Kind {
+	case parser.BlockHeading:
+		if s.Level != t.Level {
+			c += levelCost
+		}
+	case parser.BlockCodeBlock:
+		// Code is not translated.
+		if s.Raw == t.Raw {
+			return 0
+		}
+		c += codeCost
+	}
+	return c + lengthWeight*lengthMismatch(s, t)
+}
+
+// lengthMismatch returns a measure between 0 and 1 of how much the lengths
+// of the blocks differ: 0 for the same length, 1 for four times as long.
+func lengthMismatch(s, t parser.Block) float64 {
+	a := float64(utf8.RuneCountInString(s.Text) + 1)
+	b := float64(utf8.RuneCountInString(t.Text) + 1)
+	return min(1, math.Abs(math.Log(a/b))/math.Log(4))
+}
+
+// Report describes the decisions of an alignment that need checking: blocks
+// without counterpart, and paired blocks of different kinds, heading levels
+// or code. It is empty when the blocks were paired one to one without
+// mismatch.
+func Report(pairs []Pair, source, target []parser.Block) []string {
+	var notes []string
+	for _, p := range pairs {
+		switch {
+		case p.Source < 0:
+			notes = append(notes, fmt.Sprintf("translation block %d (%s) has no counterpart in the source: it gets a row of its own",
+				p.Target, describe(target[p.Target])))
+		case p.Target < 0:
+			notes = append(notes, fmt.Sprintf("source block %d (%s) has no counterpart in the translation: it is left untranslated",
+				p.Source, describe(source[p.Source])))
+		default:
+			s, t := source[p.Source], target[p.Target]
+			var what string
+			switch {
+			case s.Kind != t.Kind:
+				what = "a block of another kind"
+			case s.Kind == parser.BlockHeading && s.Level != t.Level:
+				what = "a heading of another level"
+			case s.Kind == parser.BlockCodeBlock && s.Raw != t.Raw:
+				what = "different code"
+			default:
+				continue
+			}
+			notes = append(notes, fmt.Sprintf("source block %d (%s) is paired with %s, translation block %d (%s)",
+				p.Source, describe(s), what, p.Target, describe(t)))
+		}
+	}
+	return notes
+}
+
+// describe names a block and its position, e.g. "Heading 2, line 5".
+func describe(b parser.Block) string {
+	s := b.Kind.String()
+	if b.Kind == parser.BlockHeading {
+		s += fmt.Sprintf(" %d", b.Level)
+	}
+	if b.Line > 0 {
+		s += fmt.Sprintf(", line %d", b.Line)
+	}
+	return s
+}
@@ -0,0 +1,132 @@
+package align
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"bilingual_pdf/internal/parser"
+)
+
+func parse(t *testing.T, md string) []parser.Block {
+	t.Helper()
+	blocks, err := parser.Parse([]byte(md))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return blocks
+}
+
+const source = `# Titre
+
+Un premier paragraphe assez long pour que sa longueur compte.
+
+## Section
+
+Court.
+
+` + "```\nx := 1\n```" + `
+
+Dernier paragraphe du document, de longueur moyenne.
+`
+
+func TestBlocks_SameStructure(t *testing.T) {
+	src := parse(t, source)
+	tgt := parse(t, strings.NewReplacer("Titre", "Título", "Court.", "Corto.").Replace(source))
+
+	pairs := Blocks(src, tgt)
+	for i, p := range pairs {
+		if p != (Pair{Source: i, Target: i}) {
+			t.Errorf("pair %d = %+v, want one-to-one", i, p)
+		}
+	}
+	if notes := Report(pairs, src, tgt); len(notes) != 0 {
+		t.Errorf("expected no notes, got %q", notes)
+	}
+}
+
+func TestBlocks_ExtraParagraph(t *testing.T) {
+	src := parse(t, source)
+	tgt := parse(t, `# Título
+
+Un primer párrafo bastante largo para que su longitud cuente.
+
+Un párrafo añadido por el traductor.
+
+## Sección
+
+Corto.
+
+`+"```\nx := 1\n```"+`
+
+Último párrafo del documento, de longitud media.
+`)
+
+	want := []Pair{{0, 0}, {1, 1}, {-1, 2}, {2, 3}, {3, 4}, {4, 5}, {5, 6}}
+	if got := Blocks(src, tgt); !slices.Equal(got, want) {
+		t.Errorf("Blocks = %v, want %v", got, want)
+	}
+	notes := Report(want, src, tgt)
+	if len(notes) != 1 || !strings.Contains(notes[0], "translation block 2 (Paragraph, line 5) has no counterpart") {
+		t.Errorf("unexpected notes %q", notes)
+	}
+}
+
+func TestBlocks_MissingHeading(t *testing.T) {
+	src := parse(t, source)
+	tgt := parse(t, `# Título
+
+Un primer párrafo bastante largo para que su longitud cuente.
+
+Corto.
+
+`+"```\nx := 1\n```"+`
+
+Último párrafo del documento, de longitud media.
+`)
+
+	want := []Pair{{0, 0}, {1, 1}, {2, -1}, {3, 2}, {4, 3}, {5, 4}}
+	if got := Blocks(src, tgt); !slices.Equal(got, want) {
+		t.Errorf("Blocks = %v, want %v", got, want)
+	}
+	notes := Report(want, src, tgt)
+	if len(notes) != 1 || !strings.Contains(notes[0], "source block 2 (Heading 2, line 5) has no counterpart") {
+		t.Errorf("unexpected notes %q", notes)
+	}
+}
+
+func TestBlocks_Mismatches(t *testing.T) {
+	src := parse(t, "# Titre\n\n```\na\n```\n\nTexte.\n\nFin.\n")
+	tgt := parse(t, "## Título\n\n```\nb\n```\n\n- Texto.\n\nFin.\n")
+
+	pairs := Blocks(src, tgt)
+	if want := []Pair{{0, 0}, {1, 1}, {2, 2}, {3, 3}}; !slices.Equal(pairs, want) {
+		t.Fatalf("Blocks = %v, want %v", pairs, want)
+	}
+	notes := Report(pairs, src, tgt)
+	for i, want := range []string{"a heading of another level", "different code", "a block of another kind"} {
+		if i >= len(notes) || !strings.Contains(notes[i], want) {
+			t.Errorf("note %d should mention %q, got %q", i, want, notes)
+		}
+	}
+}
+
+func TestBlocks_PartialTranslation(t *testing.T) {
+	src := parse(t, source)
+	tgt := parse(t, "# Título\n\nUn primer párrafo bastante largo para que su longitud cuente.\n")
+
+	want := []Pair{{0, 0}, {1, 1}, {2, -1}, {3, -1}, {4, -1}, {5, -1}}
+	if got := Blocks(src, tgt); !slices.Equal(got, want) {
+		t.Errorf("Blocks = %v, want %v", got, want)
+	}
+}
+
+func TestBlocks_Empty(t *testing.T) {
+	tgt := parse(t, "Uno.\n\nDos.\n")
+	if got, want := Blocks(nil, tgt), []Pair{{-1, 0}, {-1, 1}}; !slices.Equal(got, want) {
+		t.Errorf("Blocks = %v, want %v", got, want)
+	}
+	if got := Blocks(nil, nil); len(got) != 0 {
+		t.Errorf("Blocks = %v, want none", got)
+	}
+}
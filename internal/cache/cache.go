@@ -0,0 +1,267 @@
+// Package cache implements an on-disk translation memory keyed by engine and
+// its options, language pair and a hash of the text sent for translation.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProjectDir is the per-project directory; when it exists in the working
+// directory, the cache is kept inside it instead of the user cache directory.
+const ProjectDir = ".bilingual"
+
+// Entry is a cached translation.
+type Entry struct {
+	Translation string    `json:"translation"`
+	Created     time.Time `json:"created"`
+	Used        time.Time `json:"used"`
+}
+
+// Memory is an on-disk translation memory rooted at Dir. Each engine and
+// language pair is stored in its own JSON file: <Dir>/<engine>/<source>-<target>.json,
+// where the engine name carries a hash of its options (see Variant).
+type Memory struct {
+	Dir string
+}
+
+// DefaultDir returns .bilingual/cache if a .bilingual directory exists in the
+// working directory, otherwise bilingual_pdf under the user cache directory.
+func DefaultDir() (string, error) {
+	if info, err := os.Stat(ProjectDir); err == nil && info.IsDir() {
+		return filepath.Join(ProjectDir, "cache"), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locating user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "bilingual_pdf"), nil
+}
+
+// New returns a Memory rooted at dir.
+func New(dir string) *Memory {
+	return &Memory{Dir: dir}
+}
+
+// Key returns the hash used to look up text.
+func Key(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Variant returns the name under which the translations of engine with
+// the given options are cached: the engine name, followed by a hash of the
+// options if there are any, so that changing an option, such as the model
+// or the formality, does not return translations made with the old one.
+// Options with an empty value are unset, and do not count. Callers pass
+// only the options that change the translations, without credentials.
+func Variant(engine string, options map[string]string) string {
+	keys := make([]string, 0, len(options))
+	for k, v := range options {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return engine
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%s\n", k, options[k])
+	}
+	return engine + "@" + hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// Table holds the cached translations for one engine and language pair.
+type Table struct {
+	path    string
+	entries map[string]Entry
+	dirty   bool
+	now     func() time.Time
+}
+
+// Open loads the table for the given engine and language pair. A missing
+// file yields an empty table.
+func (m *Memory) Open(engine, source, target string) (*Table, error) {
+	t := &Table{
+		path:    filepath.Join(m.Dir, engine, source+"-"+target+".json"),
+		entries: map[string]Entry{},
+		now:     time.Now,
+	}
+	data, err := os.ReadFile(t.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &t.entries); err != nil {
+		return nil, fmt.Errorf("parsing cache %s: %w", t.path, err)
+	}
+	return t, nil
+}
+
+// Get returns the cached translation of text, if any.
+func (t *Table) Get(text string) (string, bool) {
+	key := Key(text)
+	e, ok := t.entries[key]
+	if !ok {
+		return "", false
+	}
+	e.Used = t.now()
+	t.entries[key] = e
+	t.dirty = true
+	return e.Translation, true
+}
+
+// Put stores the translation of text.
+func (t *Table) Put(text, translation string) {
+	now := t.now()
+	t.entries[Key(text)] = Entry{Translation: translation, Created: now, Used: now}
+	t.dirty = true
+}
+
+// Len returns the number of entries in the table.
+func (t *Table) Len() int {
+	return len(t.entries)
+}
+
+// Save writes the table to disk if it changed.
+func (t *Table) Save() error {
+	if !t.dirty {
+		return nil
+	}
+	if err := writeJSON(t.path, t.entries); err != nil {
+		return fmt.Errorf("saving cache: %w", err)
+	}
+	t.dirty = false
+	return nil
+}
+
+// TableStats summarizes one cache file.
+type TableStats struct {
+	Engine  string
+	Source  string
+	Target  string
+	Entries int
+	Bytes   int64
+	Oldest  time.Time // least recent use
+	Newest  time.Time // most recent use
+}
+
+// Stats returns a summary of every table in the memory, sorted by engine and pair.
+func (m *Memory) Stats() ([]TableStats, error) {
+	var stats []TableStats
+	err := m.walk(func(path, engine, source, target string, entries map[string]Entry) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		s := TableStats{Engine: engine, Source: source, Target: target, Entries: len(entries), Bytes: info.Size()}
+		for _, e := range entries {
+			if s.Oldest.IsZero() || e.Used.Before(s.Oldest) {
+				s.Oldest = e.Used
+			}
+			if e.Used.After(s.Newest) {
+				s.Newest = e.Used
+			}
+		}
+		stats = append(stats, s)
+		return nil
+	})
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Engine != stats[j].Engine {
+			return stats[i].Engine < stats[j].Engine
+		}
+		return stats[i].Source+stats[i].Target < stats[j].Source+stats[j].Target
+	})
+	return stats, err
+}
+
+// Prune removes entries last used before the cutoff and returns how many were removed.
+func (m *Memory) Prune(cutoff time.Time) (int, error) {
+	removed := 0
+	err := m.walk(func(path, _, _, _ string, entries map[string]Entry) error {
+		before := len(entries)
+		for k, e := range entries {
+			if e.Used.Before(cutoff) {
+				delete(entries, k)
+			}
+		}
+		if len(entries) == before {
+			return nil
+		}
+		removed += before - len(entries)
+		if len(entries) == 0 {
+			return os.Remove(path)
+		}
+		return writeJSON(path, entries)
+	})
+	return removed, err
+}
+
+// Clear deletes the whole memory.
+func (m *Memory) Clear() error {
+	return os.RemoveAll(m.Dir)
+}
+
+// walk calls fn for every table file in the memory.
+func (m *Memory) walk(fn func(path, engine, source, target string, entries map[string]Entry) error) error {
+	files, err := filepath.Glob(filepath.Join(m.Dir, "*", "*-*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		pair := strings.TrimSuffix(filepath.Base(path), ".json")
+		source, target, _ := strings.Cut(pair, "-")
+		engine := filepath.Base(filepath.Dir(path))
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading cache: %w", err)
+		}
+		entries := map[string]Entry{}
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return fmt.Errorf("parsing cache %s: %w", path, err)
+		}
+		if err := fn(path, engine, source, target, entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSON atomically replaces path with the JSON encoding of v.
+func writeJSON(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*.json")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTable_PutGetSave(t *testing.T) {
+	m := New(t.TempDir())
+
+	table, err := m.Open("google", "fr", "es")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, ok := table.Get("Bonjour"); ok {
+		t.Fatal("empty table should miss")
+	}
+	table.Put("Bonjour", "Hola")
+	if err := table.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	reopened, err := m.Open("google", "fr", "es")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if got, ok := reopened.Get("Bonjour"); !ok || got != "Hola" {
+		t.Errorf("expected cached Hola, got %q (hit=%v)", got, ok)
+	}
+
+	// Other engines and pairs are separate tables
+	other, err := m.Open("deepl", "fr", "es")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, ok := other.Get("Bonjour"); ok {
+		t.Error("tables of different engines should not share entries")
+	}
+}
+
+func TestMemory_StatsPruneClear(t *testing.T) {
+	m := New(t.TempDir())
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	table, _ := m.Open("google", "fr", "es")
+	table.now = func() time.Time { return old }
+	table.Put("vieux", "viejo")
+	table.now = time.Now
+	table.Put("nouveau", "nuevo")
+	if err := table.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	stats, err := m.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Entries != 2 || stats[0].Engine != "google" || stats[0].Target != "es" {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	removed, err := m.Prune(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("expected 1 pruned entry, got %d", removed)
+	}
+	table, _ = m.Open("google", "fr", "es")
+	if _, ok := table.Get("vieux"); ok {
+		t.Error("old entry should have been pruned")
+	}
+	if _, ok := table.Get("nouveau"); !ok {
+		t.Error("recent entry should have been kept")
+	}
+
+	if err := m.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	stats, err = m.Stats()
+	if err != nil || len(stats) != 0 {
+		t.Errorf("expected empty stats after Clear, got %+v (%v)", stats, err)
+	}
+}
+
+func TestVariant(t *testing.T) {
+	if got := Variant("deepl", nil); got != "deepl" {
+		t.Errorf("Variant without options = %q, want deepl", got)
+	}
+	if got := Variant("deepl", map[string]string{"formality": ""}); got != "deepl" {
+		t.Errorf("unset options should not count, got %q", got)
+	}
+
+	m := New(t.TempDir())
+	less := Variant("deepl", map[string]string{"formality": "less", "tag_handling": "html"})
+	table, _ := m.Open(less, "fr", "es")
+	table.Put("Bonjour", "Hola")
+	if err := table.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	more, _ := m.Open(Variant("deepl", map[string]string{"formality": "more", "tag_handling": "html"}), "fr", "es")
+	if _, ok := more.Get("Bonjour"); ok {
+		t.Error("changing an option should miss the cache")
+	}
+	same, _ := m.Open(Variant("deepl", map[string]string{"tag_handling": "html", "formality": "less"}), "fr", "es")
+	if got, ok := same.Get("Bonjour"); !ok || got != "Hola" {
+		t.Errorf("the same options should hit the cache, got %q (hit=%v)", got, ok)
+	}
+}
+
+func TestKey_Stable(t *testing.T) {
+	if Key("a") != Key("a") || Key("a") == Key("b") {
+		t.Error("Key should be deterministic and content-dependent")
+	}
+}
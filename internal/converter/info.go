@@ -0,0 +1,93 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+	"unicode/utf16"
+)
+
+// Info is the document information written into the PDF.
+type Info struct {
+	Title  string
+	Author string
+	Date   time.Time // creation date, zero if unknown
+}
+
+var (
+	startxrefPattern = regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF\s*$`)
+	trailerPattern   = regexp.MustCompile(`(?s)^trailer\s*<<(.*)>>\s*startxref\s+\d+\s+%%EOF\s*$`)
+	sizePattern      = regexp.MustCompile(`/Size\s+(\d+)`)
+	rootPattern      = regexp.MustCompile(`/Root\s+(\d+\s+\d+\s+R)`)
+	idPattern        = regexp.MustCompile(`(?s)/ID\s*(\[.*?\])`)
+)
+
+// SetInfo returns the PDF with its document information replaced by info,
+// appended as an incremental update so that the rest of the file is left
+// as it is. It fails on a PDF without a classic trailer, such as one with
+// a cross-reference stream.
+func SetInfo(pdf []byte, info Info) ([]byte, error) {
+	m := startxrefPattern.FindSubmatch(pdf)
+	var t [][]byte
+	if i := bytes.LastIndex(pdf, []byte("trailer")); i >= 0 {
+		t = trailerPattern.FindSubmatch(pdf[i:])
+	}
+	if m == nil || t == nil {
+		return nil, fmt.Errorf("setting PDF info: no trailer found")
+	}
+	prev := string(m[1])
+	size := sizePattern.FindSubmatch(t[1])
+	root := rootPattern.FindSubmatch(t[1])
+	if size == nil || root == nil {
+		return nil, fmt.Errorf("setting PDF info: incomplete trailer")
+	}
+	n, err := strconv.Atoi(string(size[1]))
+	if err != nil {
+		return nil, fmt.Errorf("setting PDF info: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(pdf)
+	if !bytes.HasSuffix(pdf, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	objOffset := buf.Len()
+	fmt.Fprintf(&buf, "%d 0 obj\n<<", n)
+	for _, f := range []struct{ key, value string }{
+		{"Title", info.Title},
+		{"Author", info.Author},
+		{"Producer", "bilingual_pdf"},
+	} {
+		if f.value != "" {
+			fmt.Fprintf(&buf, " /%s %s", f.key, textString(f.value))
+		}
+	}
+	if !info.Date.IsZero() {
+		fmt.Fprintf(&buf, " /CreationDate (D:%s)", info.Date.Format("20060102150405"))
+	}
+	buf.WriteString(" >>\nendobj\n")
+
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n%d 1\n%010d 00000 n \n", n, objOffset)
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %s /Info %d 0 R /Prev %s", n+1, root[1], n, prev)
+	if id := idPattern.FindSubmatch(t[1]); id != nil {
+		fmt.Fprintf(&buf, " /ID %s", id[1])
+	}
+	fmt.Fprintf(&buf, " >>\nstartxref\n%d\n%%%%EOF\n", xrefOffset)
+	return buf.Bytes(), nil
+}
+
+// textString encodes s as a PDF text string: UTF-16BE with a byte order
+// mark, in hex.
+func textString(s string) string {
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, 2, 2+2*len(units))
+	b[0], b[1] = 0xFE, 0xFF
+	for _, u := range units {
+		b = append(b, byte(u>>8), byte(u))
+	}
+	return "<" + hex.EncodeToString(b) + ">"
+}
@@ -0,0 +1,66 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const minimalPDF = `%PDF-1.4
+1 0 obj
+<< /Type /Catalog /Pages 2 0 R >>
+endobj
+2 0 obj
+<< /Type /Pages /Kids [] /Count 0 >>
+endobj
+xref
+0 3
+0000000000 65535 f 
+0000000009 00000 n 
+0000000058 00000 n 
+trailer
+<< /Size 3 /Root 1 0 R /ID [<ab> <cd>] >>
+startxref
+110
+%%EOF
+`
+
+func TestSetInfo(t *testing.T) {
+	info := Info{Title: "Été", Author: "Marie", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	pdf, err := SetInfo([]byte(minimalPDF), info)
+	if err != nil {
+		t.Fatalf("SetInfo failed: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte(minimalPDF)) {
+		t.Fatal("the original PDF should be kept as it is")
+	}
+	update := string(pdf[len(minimalPDF):])
+	for _, want := range []string{
+		"3 0 obj\n<< /Title <feff00c9007400e9> /Author <feff004d0061007200690065>",
+		"/CreationDate (D:20240501000000)",
+		fmt.Sprintf("xref\n3 1\n%010d 00000 n \n", len(minimalPDF)),
+		"trailer\n<< /Size 4 /Root 1 0 R /Info 3 0 R /Prev 110 /ID [<ab> <cd>] >>",
+	} {
+		if !strings.Contains(update, want) {
+			t.Errorf("update should contain %q, got:\n%s", want, update)
+		}
+	}
+
+	m := startxrefPattern.FindStringSubmatch(update)
+	if m == nil {
+		t.Fatal("no startxref in the update")
+	}
+	offset, _ := strconv.Atoi(m[1])
+	if !strings.HasPrefix(string(pdf[offset:]), "xref\n3 1\n") {
+		t.Errorf("startxref should point at the new xref section")
+	}
+}
+
+func TestSetInfo_NoTrailer(t *testing.T) {
+	if _, err := SetInfo([]byte("%PDF-1.5\nnot a pdf"), Info{Title: "x"}); err == nil {
+		t.Error("expected an error without a trailer")
+	}
+}
@@ -0,0 +1,317 @@
+// Package glossary loads per-language-pair term lists and enforces them
+// around machine translation: forced term mappings and do-not-translate terms
+// are masked before translation and substituted afterwards.
+package glossary
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"bilingual_pdf/internal/placeholder"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Term is a glossary entry. A Term with Keep set is copied verbatim.
+type Term struct {
+	Source string
+	Target string
+	Keep   bool // do not translate
+}
+
+// Glossary is the list of terms for one language pair.
+type Glossary struct {
+	Source string
+	Target string
+	Terms  []Term // longest source first, so longer terms win over their prefixes
+}
+
+// yamlFile is the YAML glossary format.
+type yamlFile struct {
+	Source string            `yaml:"source"`
+	Target string            `yaml:"target"`
+	Terms  map[string]string `yaml:"terms"`
+	Keep   []string          `yaml:"keep"`
+}
+
+// Load reads a glossary for the source → target pair. The format is chosen
+// from the extension: .csv, .tsv, .yaml or .yml.
+//
+// CSV and TSV files hold one term per row: source term, target term. An
+// empty target (or one equal to the source) marks a do-not-translate term.
+// An optional header row of language codes (e.g. "fr,es" or "en,fr,es,de")
+// selects the source and target columns; lines starting with # are comments.
+//
+// YAML files have source and target language codes, a terms map and a keep
+// list of do-not-translate terms.
+func Load(path, source, target string) (*Glossary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading glossary: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var g *Glossary
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		g, err = parseDelimited(f, ',', source, target)
+	case ".tsv":
+		g, err = parseDelimited(f, '\t', source, target)
+	case ".yaml", ".yml":
+		g, err = parseYAML(f, source, target)
+	default:
+		return nil, fmt.Errorf("glossary must be .csv, .tsv, .yaml or .yml, got %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing glossary %s: %w", path, err)
+	}
+	g.sortTerms()
+	return g, nil
+}
+
+func parseDelimited(r io.Reader, delim rune, source, target string) (*Glossary, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = delim
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	if delim == '\t' {
+		cr.LazyQuotes = true
+	}
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Glossary{Source: source, Target: target}
+	srcCol, tgtCol := 0, 1
+	if len(records) > 0 && isHeader(records[0], source, target) {
+		srcCol, tgtCol = -1, -1
+		for i, code := range records[0] {
+			switch strings.ToLower(strings.TrimSpace(code)) {
+			case source:
+				srcCol = i
+			case target:
+				tgtCol = i
+			}
+		}
+		if srcCol < 0 || tgtCol < 0 {
+			return nil, fmt.Errorf("header %q has no %s and %s columns", strings.Join(records[0], ","), source, target)
+		}
+		records = records[1:]
+	}
+
+	for _, rec := range records {
+		if srcCol >= len(rec) {
+			continue
+		}
+		src := strings.TrimSpace(rec[srcCol])
+		if src == "" {
+			continue
+		}
+		tgt := ""
+		if tgtCol < len(rec) {
+			tgt = strings.TrimSpace(rec[tgtCol])
+		}
+		g.add(src, tgt)
+	}
+	return g, nil
+}
+
+// isHeader reports whether a row is a header of language codes that
+// mentions the source or target language.
+func isHeader(row []string, source, target string) bool {
+	mentions := false
+	for _, cell := range row {
+		code := strings.ToLower(strings.TrimSpace(cell))
+		if len(code) < 2 || len(code) > 3 || strings.IndexFunc(code, func(r rune) bool { return r < 'a' || r > 'z' }) >= 0 {
+			return false
+		}
+		if code == source || code == target {
+			mentions = true
+		}
+	}
+	return mentions
+}
+
+func parseYAML(r io.Reader, source, target string) (*Glossary, error) {
+	var y yamlFile
+	if err := yaml.NewDecoder(r).Decode(&y); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	g := &Glossary{Source: source, Target: target}
+	reversed := false
+	if y.Source != "" || y.Target != "" {
+		switch {
+		case y.Source == source && y.Target == target:
+		case y.Source == target && y.Target == source:
+			reversed = true
+		default:
+			return nil, fmt.Errorf("glossary is for %s → %s, not %s → %s", y.Source, y.Target, source, target)
+		}
+	}
+
+	for src, tgt := range y.Terms {
+		if reversed {
+			src, tgt = tgt, src
+		}
+		g.add(strings.TrimSpace(src), strings.TrimSpace(tgt))
+	}
+	for _, term := range y.Keep {
+		g.add(strings.TrimSpace(term), "")
+	}
+	return g, nil
+}
+
+func (g *Glossary) add(src, tgt string) {
+	if src == "" {
+		return
+	}
+	if tgt == "" || tgt == src {
+		g.Terms = append(g.Terms, Term{Source: src, Target: src, Keep: true})
+		return
+	}
+	g.Terms = append(g.Terms, Term{Source: src, Target: tgt})
+}
+
+func (g *Glossary) sortTerms() {
+	sort.SliceStable(g.Terms, func(i, j int) bool {
+		return utf8.RuneCountInString(g.Terms[i].Source) > utf8.RuneCountInString(g.Terms[j].Source)
+	})
+}
+
+// Mask replaces every glossary term in text with a token from set standing
+// for the term's target form, and returns the masked text.
+func (g *Glossary) Mask(text string, set *placeholder.Set) string {
+	for _, term := range g.Terms {
+		text = replaceTerm(text, term.Source, func(match string) string {
+			return set.Add(targetForm(term, match))
+		})
+	}
+	return text
+}
+
+// Issue describes a glossary term of the source block whose expected
+// counterpart is missing from the translated block.
+type Issue struct {
+	Term     Term
+	Expected string
+}
+
+// Check returns the terms present in source whose expected target form does
+// not appear in translated.
+func (g *Glossary) Check(source, translated string) []Issue {
+	var issues []Issue
+	lowerTranslated := strings.ToLower(translated)
+	for _, term := range g.Terms {
+		if len(findTerm(source, term.Source)) == 0 {
+			continue
+		}
+		if !strings.Contains(lowerTranslated, strings.ToLower(term.Target)) {
+			issues = append(issues, Issue{Term: term, Expected: term.Target})
+		}
+	}
+	return issues
+}
+
+// targetForm returns the text that replaces match: the term's target, with
+// the first letter capitalized if the matched source was capitalized and
+// the glossary entry was not.
+func targetForm(term Term, match string) string {
+	if term.Keep {
+		return match
+	}
+	m, _ := utf8.DecodeRuneInString(match)
+	s, _ := utf8.DecodeRuneInString(term.Source)
+	if unicode.IsUpper(m) && !unicode.IsUpper(s) {
+		r, size := utf8.DecodeRuneInString(term.Target)
+		return string(unicode.ToUpper(r)) + term.Target[size:]
+	}
+	return term.Target
+}
+
+// replaceTerm replaces whole-word, case-insensitive occurrences of term in text.
+func replaceTerm(text, term string, repl func(match string) string) string {
+	matches := findTerm(text, term)
+	if len(matches) == 0 {
+		return text
+	}
+	var buf strings.Builder
+	last := 0
+	for _, m := range matches {
+		buf.WriteString(text[last:m[0]])
+		buf.WriteString(repl(text[m[0]:m[1]]))
+		last = m[1]
+	}
+	buf.WriteString(text[last:])
+	return buf.String()
+}
+
+// findTerm returns the byte ranges of whole-word, case-insensitive
+// occurrences of term in text. Occurrences inside placeholder tokens are
+// skipped.
+func findTerm(text, term string) [][2]int {
+	if term == "" {
+		return nil
+	}
+	lowerText := strings.ToLower(text)
+	lowerTerm := strings.ToLower(term)
+	if len(lowerText) != len(text) || len(lowerTerm) != len(term) {
+		// Lowercasing changed byte lengths (rare scripts); fall back to an exact match.
+		lowerText, lowerTerm = text, term
+	}
+
+	var matches [][2]int
+	for start := 0; start < len(lowerText); {
+		i := strings.Index(lowerText[start:], lowerTerm)
+		if i < 0 {
+			break
+		}
+		from, to := start+i, start+i+len(lowerTerm)
+		if isBoundary(text, from, to) && !insideToken(text, from) {
+			matches = append(matches, [2]int{from, to})
+			start = to
+			continue
+		}
+		start = from + 1
+	}
+	return matches
+}
+
+// isBoundary reports whether text[from:to] is delimited by non-word characters.
+func isBoundary(text string, from, to int) bool {
+	if from > 0 {
+		r, _ := utf8.DecodeLastRuneInString(text[:from])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	if to < len(text) {
+		r, _ := utf8.DecodeRuneInString(text[to:])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// insideToken reports whether position i lies within a {{X0}} placeholder token.
+func insideToken(text string, i int) bool {
+	open := strings.LastIndex(text[:i], "{{")
+	if open < 0 {
+		return false
+	}
+	return !strings.Contains(text[open:i], "}}")
+}
@@ -0,0 +1,112 @@
+package glossary
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bilingual_pdf/internal/placeholder"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_CSV(t *testing.T) {
+	path := writeFile(t, "glossary.csv", "# product terms\nfr,es\nfromage de chèvre,queso de cabra\nbilingual_pdf,\n")
+	g, err := Load(path, "fr", "es")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(g.Terms) != 2 {
+		t.Fatalf("expected 2 terms, got %+v", g.Terms)
+	}
+	if g.Terms[0].Source != "fromage de chèvre" || g.Terms[0].Target != "queso de cabra" {
+		t.Errorf("unexpected first term %+v", g.Terms[0])
+	}
+	if !g.Terms[1].Keep {
+		t.Errorf("empty target should mark a do-not-translate term, got %+v", g.Terms[1])
+	}
+}
+
+func TestLoad_TSVMultiLanguageHeader(t *testing.T) {
+	path := writeFile(t, "terms.tsv", "en\tfr\tes\nwine\tvin\tvino\n")
+	g, err := Load(path, "fr", "es")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(g.Terms) != 1 || g.Terms[0].Source != "vin" || g.Terms[0].Target != "vino" {
+		t.Errorf("header should select the fr and es columns, got %+v", g.Terms)
+	}
+
+	if _, err := Load(path, "fr", "de"); err == nil {
+		t.Error("header without the target language should fail")
+	}
+}
+
+func TestLoad_YAML(t *testing.T) {
+	path := writeFile(t, "glossary.yaml", "source: es\ntarget: fr\nterms:\n  vino tinto: vin rouge\nkeep:\n  - Chrome\n")
+
+	// Reversed pair: terms are swapped.
+	g, err := Load(path, "fr", "es")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if g.Terms[0].Source != "vin rouge" || g.Terms[0].Target != "vino tinto" {
+		t.Errorf("expected reversed term, got %+v", g.Terms[0])
+	}
+
+	if _, err := Load(path, "en", "de"); err == nil {
+		t.Error("glossary for another pair should fail")
+	}
+}
+
+func TestGlossary_MaskRestore(t *testing.T) {
+	g := &Glossary{Source: "fr", Target: "es"}
+	g.add("vin", "vino")
+	g.add("vin rouge", "vino tinto")
+	g.add("bilingual_pdf", "")
+	g.sortTerms()
+
+	set := placeholder.NewSet('G')
+	masked := g.Mask("Vin rouge et vin blanc avec bilingual_pdf, pas de vinaigre.", set)
+	// Longest terms are masked first.
+	if masked != "{{G1}} et {{G2}} blanc avec {{G0}}, pas de vinaigre." {
+		t.Fatalf("unexpected masked text %q", masked)
+	}
+
+	restored, missing := set.Restore("{{G1}} y {{G2}} blanco con {{G0}}, sin vinagre.")
+	if restored != "Vino tinto y vino blanco con bilingual_pdf, sin vinagre." {
+		t.Errorf("unexpected restored text %q", restored)
+	}
+	if len(missing) != 0 {
+		t.Errorf("unexpected missing values %q", missing)
+	}
+}
+
+func TestGlossary_Check(t *testing.T) {
+	g := &Glossary{}
+	g.add("vin rouge", "vino tinto")
+	g.add("Chrome", "")
+
+	issues := g.Check("Un vin rouge avec Chrome.", "Un vino rojo con Chrome.")
+	if len(issues) != 1 || issues[0].Expected != "vino tinto" {
+		t.Errorf("expected one issue for vin rouge, got %+v", issues)
+	}
+	if issues := g.Check("Sans termes.", "Sin términos."); len(issues) != 0 {
+		t.Errorf("expected no issues, got %+v", issues)
+	}
+}
+
+func TestLoad_UnsupportedExtension(t *testing.T) {
+	path := writeFile(t, "glossary.txt", "a,b\n")
+	if _, err := Load(path, "fr", "es"); err == nil || !strings.Contains(err.Error(), ".csv") {
+		t.Errorf("expected unsupported format error, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+// Package images embeds the local images of rendered HTML as data URIs, so
+// that the browser printing the PDF from about:blank can show them.
+package images
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// srcPattern matches the src attribute of an img tag, as written by
+// goldmark or by hand in an HTML block.
+var srcPattern = regexp.MustCompile(`(<img\b[^>]*?\ssrc=)("[^"]*"|'[^']*')`)
+
+// Embedder replaces the paths of local images with data URIs. Paths are
+// resolved against Dir, and a variant of an image for the language of the
+// column, such as diagram.es.png for diagram.png, is used when it exists.
+// Images outside Dir, by an absolute path, a "../" path or a symbolic
+// link, are not embedded unless AllowOutside is set, so that a document
+// from elsewhere cannot pull arbitrary local files into the PDF.
+type Embedder struct {
+	Dir          string    // directory of the input file
+	Langs        []string  // language codes that may end the name of an image variant
+	Warn         io.Writer // receives a warning for each image that cannot be read
+	AllowOutside bool      // embed images outside Dir too
+
+	uris   map[string]string // data URI of each file read
+	warned map[string]bool
+}
+
+// NewEmbedder returns an Embedder for the images of a document in dir, in
+// the given languages.
+func NewEmbedder(dir string, langs []string, warn io.Writer) *Embedder {
+	return &Embedder{
+		Dir:    dir,
+		Langs:  langs,
+		Warn:   warn,
+		uris:   map[string]string{},
+		warned: map[string]bool{},
+	}
+}
+
+// Embed returns an HTML fragment in the language lang with its local
+// images embedded. Remote images and data URIs are left alone, as are
+// images that cannot be read.
+func (e *Embedder) Embed(fragment, lang string) string {
+	return srcPattern.ReplaceAllStringFunc(fragment, func(tag string) string {
+		m := srcPattern.FindStringSubmatch(tag)
+		src := html.UnescapeString(m[2][1 : len(m[2])-1])
+		if isRemote(src) {
+			return tag
+		}
+		uri, ok := e.dataURI(src, lang)
+		if !ok {
+			return tag
+		}
+		return m[1] + `"` + uri + `"`
+	})
+}
+
+// dataURI returns the data URI of the image at src, or of its variant in
+// lang.
+func (e *Embedder) dataURI(src, lang string) (string, bool) {
+	path := src
+	if p, err := url.PathUnescape(src); err == nil {
+		path = p
+	}
+	path = strings.TrimPrefix(path, "file://")
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(e.Dir, filepath.FromSlash(path))
+	}
+	if v := e.variant(path, lang); v != "" {
+		path = v
+	}
+	if uri, ok := e.uris[path]; ok {
+		return uri, true
+	}
+
+	var data []byte
+	err := errOutside
+	if e.AllowOutside || e.inside(path) {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		if !e.warned[path] && e.Warn != nil {
+			fmt.Fprintf(e.Warn, "Warning: image %s cannot be embedded: %v\n", src, err)
+		}
+		e.warned[path] = true
+		return "", false
+	}
+	mediaType := mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+	if mediaType == "" {
+		mediaType = http.DetectContentType(data)
+	}
+	uri := "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)
+	e.uris[path] = uri
+	return uri, true
+}
+
+// errOutside is the reason an image outside the document directory is not
+// embedded.
+var errOutside = errors.New("outside the directory of the document (use --allow-outside-images to embed it)")
+
+// inside reports whether path, symbolic links resolved, is in Dir or below.
+func (e *Embedder) inside(path string) bool {
+	dir, err := filepath.Abs(e.Dir)
+	if err != nil {
+		return false
+	}
+	if d, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = d
+	}
+	if p, err := filepath.EvalSymlinks(path); err == nil {
+		path = p
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// variant returns the path of the variant of an image in lang, such as
+// diagram.es.png for diagram.png or diagram.fr.png, or "" if there is
+// none.
+func (e *Embedder) variant(path, lang string) string {
+	if lang == "" {
+		return ""
+	}
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(path, ext)
+	for _, l := range e.Langs {
+		if strings.HasSuffix(stem, "."+l) {
+			stem = strings.TrimSuffix(stem, "."+l)
+			break
+		}
+	}
+	v := stem + "." + lang + ext
+	if v == path {
+		return ""
+	}
+	if _, err := os.Stat(v); err != nil {
+		return ""
+	}
+	return v
+}
+
+// isRemote reports whether src is loaded by the browser itself: a URL with
+// a scheme other than file, or a protocol-relative URL.
+func isRemote(src string) bool {
+	if strings.HasPrefix(src, "//") {
+		return true
+	}
+	u, err := url.Parse(src)
+	if err != nil {
+		return false
+	}
+	return len(u.Scheme) > 1 && u.Scheme != "file"
+}
@@ -0,0 +1,120 @@
+package images
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func dataURI(mediaType, content string) string {
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString([]byte(content))
+}
+
+func TestEmbed(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "img/logo.png", "logo")
+	writeFile(t, dir, "img/my diagram.png", "diagram")
+	writeFile(t, dir, "img/my diagram.es.png", "diagrama")
+	writeFile(t, dir, "chart.fr.svg", "graphique")
+	writeFile(t, dir, "chart.es.svg", "gráfico")
+
+	var warn bytes.Buffer
+	e := NewEmbedder(dir, []string{"fr", "es"}, &warn)
+	tests := []struct {
+		name, fragment, lang, want string
+	}{
+		{
+			name:     "relative path",
+			fragment: `<p><img src="img/logo.png" alt="Logo"></p>`,
+			lang:     "fr",
+			want:     `<p><img src="` + dataURI("image/png", "logo") + `" alt="Logo"></p>`,
+		},
+		{
+			name:     "variant of the target language",
+			fragment: `<img src="img/my%20diagram.png" alt="Diagrama">`,
+			lang:     "es",
+			want:     `<img src="` + dataURI("image/png", "diagrama") + `" alt="Diagrama">`,
+		},
+		{
+			name:     "no variant of the source language",
+			fragment: `<img src="img/my%20diagram.png" alt="Diagramme">`,
+			lang:     "fr",
+			want:     `<img src="` + dataURI("image/png", "diagram") + `" alt="Diagramme">`,
+		},
+		{
+			name:     "variant of a variant",
+			fragment: `<img alt="" src='chart.fr.svg'>`,
+			lang:     "es",
+			want:     `<img alt="" src="` + dataURI("image/svg+xml", "gráfico") + `">`,
+		},
+		{
+			name:     "remote image",
+			fragment: `<img src="https://example.com/logo.png">`,
+			lang:     "es",
+			want:     `<img src="https://example.com/logo.png">`,
+		},
+		{
+			name:     "missing image",
+			fragment: `<img src="missing.png">`,
+			lang:     "es",
+			want:     `<img src="missing.png">`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.Embed(tt.fragment, tt.lang); got != tt.want {
+				t.Errorf("Embed(%q) = %q, want %q", tt.fragment, got, tt.want)
+			}
+		})
+	}
+
+	e.Embed(`<img src="missing.png">`, "fr")
+	if n := strings.Count(warn.String(), "Warning:"); n != 1 {
+		t.Errorf("expected one warning for the missing image, got %d:\n%s", n, warn.String())
+	}
+}
+
+func TestEmbed_OutsideDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "doc")
+	writeFile(t, dir, "inside.png", "inside")
+	writeFile(t, root, "secret.png", "secret")
+	if err := os.Symlink(filepath.Join(root, "secret.png"), filepath.Join(dir, "link.png")); err != nil {
+		t.Fatal(err)
+	}
+
+	var warn bytes.Buffer
+	e := NewEmbedder(dir, nil, &warn)
+	for _, src := range []string{"../secret.png", filepath.Join(root, "secret.png"), "link.png"} {
+		fragment := `<img src="` + src + `">`
+		if got := e.Embed(fragment, ""); got != fragment {
+			t.Errorf("Embed(%q) = %q, want it left alone", fragment, got)
+		}
+	}
+	if !strings.Contains(warn.String(), "outside the directory") {
+		t.Errorf("expected a warning about images outside the directory, got:\n%s", warn.String())
+	}
+	if got := e.Embed(`<img src="sub/../inside.png">`, ""); got != `<img src="`+dataURI("image/png", "inside")+`">` {
+		t.Errorf("an image inside the directory should be embedded, got %q", got)
+	}
+
+	e = NewEmbedder(dir, nil, &warn)
+	e.AllowOutside = true
+	if got := e.Embed(`<img src="../secret.png">`, ""); got != `<img src="`+dataURI("image/png", "secret")+`">` {
+		t.Errorf("AllowOutside should embed an image outside the directory, got %q", got)
+	}
+}
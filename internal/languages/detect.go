@@ -0,0 +1,324 @@
+package languages
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Auto is the source language code that asks for automatic detection.
+const Auto = "auto"
+
+// Detection is the result of language detection.
+type Detection struct {
+	Code       string  // detected language code, "" if undetermined
+	Confidence float64 // between 0 and 1
+}
+
+// frequentWords lists the most frequent words of each Latin-script
+// language. Scripts used by a single supported language are identified by
+// their letters alone.
+var frequentWords = map[string]string{
+	"af": "die het en van is nie in dat te wat 'n om vir met op ek sy hy was ons hulle word kan by ook maar sal as aan baie hierdie uit",
+	"ca": "el la els les de del i que en un una per amb no és al als més com però seu seva són també aquest aquesta ha han molt fins pel dels va",
+	"cs": "a se na je že v s to jsou není jako ale by pro o do k z jak jsem byl tak už jeho které který která také nebo při od po ve",
+	"da": "og i at det er en til på der med af for ikke den de som har et jeg var fra han kan vi men skal sig efter også hvad eller blev mig meget noget nu",
+	"de": "der die und in den von zu das mit sich des auf für ist im dem nicht ein eine als auch es an werden aus er hat dass sie nach wird bei einer um noch wie über",
+	"en": "the of and to in is that it for was on are as with by be this from have not or an at which but they you he we has their",
+	"es": "de la que el en y los se del las un por con no una su para es al lo como más pero sus le ya o este fue ha también entre cuando muy",
+	"et": "ja on ei et see oli ka kui mis ta aga nii ning kes või mida seda siis veel tema oma üks pole ole mitte kõik selle",
+	"fi": "ja on ei se että oli hän mutta ovat kun myös tai ole joka sen niin mitä jos kuin vain voi olla minä tämä mukaan sekä",
+	"fr": "de la le et les des en un une du est que qui dans pour pas au sur par ne se plus il avec ce sont ou aux cette mais nous vous leur elle",
+	"hr": "i je u se na da su za od s kao ne to sa ali ili koji koja koje bio biti samo još nije što kako ima iz",
+	"hu": "a az és hogy nem is egy meg van de ez volt már csak mint ki még el azt vagy ha kell lesz minden sem után nagyon",
+	"id": "yang dan di ini itu dengan untuk tidak dari dalam akan pada juga saya ke karena ada bisa oleh atau mereka kami sudah adalah bahwa tapi",
+	"it": "di e il la che in a per un è non una del con sono le si da i al della lo come più ma anche gli questo alla nel dei",
+	"lt": "ir yra kad į su tai iš ne o bet kaip jis ji buvo jo per apie dar taip tik nuo kai jau savo arba",
+	"lv": "un ir ka ar no uz par kas bet lai tas to arī nav bija viņš viņa vai kā pie jau tikai ļoti savu",
+	"ms": "yang dan di ini itu dengan untuk tidak dari dalam akan pada juga saya ke kerana ada boleh oleh atau mereka kami sudah ialah bahawa tetapi",
+	"nl": "de het een en van in is dat op te zijn met voor niet aan er die als ook maar om bij door worden wordt naar dan nog wat",
+	"no": "og i det er som en til på å med av for ikke den har de jeg var fra han kan vi men skal seg etter også hva eller ble meg veldig noe mye",
+	"pl": "i w nie na się z do to że jest a o jak ale po co tak od przez dla są być jego czy już tylko które który która oraz",
+	"pt": "de a o que e do da em um para é com não uma os no se na por mais as dos como mas ao ele das à seu sua ou também são",
+	"ro": "și de la în a cu pe nu că o un este se care mai din ca sunt pentru dar sau fi lui al ei ce fost acest această",
+	"sk": "a v sa na je že s to sú nie ako ale by pre o do k z som bol tak už jeho ktoré ktorý ktorá tiež alebo pri od po vo",
+	"sl": "in je v na se da za so z ne ki to s pa tudi ali od kot po bi bil smo sem še samo lahko ter kar",
+	"sv": "och i att det som en på är av för med till den inte har de jag var om ett men han kan vi så också eller från efter",
+	"tr": "ve bir bu da de için ile ne çok olarak daha gibi ama en kadar olan var o sonra ancak her değil ben biz şey veya",
+	"vi": "của và là có không được trong những cho các một người đã với này để khi thì đến như cũng từ về sẽ",
+}
+
+// distinctiveLetters lists letters that are characteristic of a few
+// Latin-script languages.
+var distinctiveLetters = map[rune]string{
+	'ñ': "es", 'ã': "pt", 'õ': "pt et", 'ç': "fr pt ca tr", 'ß': "de",
+	'å': "da no sv", 'ø': "da no", 'æ': "da no",
+	'ő': "hu", 'ű': "hu", 'ł': "pl", 'ś': "pl", 'ź': "pl", 'ż': "pl", 'ń': "pl",
+	'ą': "pl lt", 'ę': "pl lt", 'ė': "lt", 'į': "lt", 'ų': "lt",
+	'ř': "cs", 'ů': "cs", 'ě': "cs", 'ľ': "sk", 'ĺ': "sk", 'ŕ': "sk", 'ô': "sk fr",
+	'ğ': "tr", 'ş': "tr", 'ı': "tr", 'ș': "ro", 'ț': "ro", 'ă': "ro vi",
+	'ā': "lv", 'ē': "lv", 'ī': "lv", 'ķ': "lv", 'ļ': "lv", 'ņ': "lv", 'ģ': "lv",
+	'ć': "hr", 'đ': "hr vi", 'ơ': "vi", 'ư': "vi", 'ạ': "vi", 'ả': "vi", 'ấ': "vi",
+	'ầ': "vi", 'ậ': "vi", 'ắ': "vi", 'ế': "vi", 'ề': "vi", 'ệ': "vi", 'ị': "vi",
+	'ọ': "vi", 'ố': "vi", 'ồ': "vi", 'ộ': "vi", 'ớ': "vi", 'ờ': "vi", 'ợ': "vi",
+	'ụ': "vi", 'ủ': "vi", 'ứ': "vi", 'ừ': "vi", 'ự': "vi",
+	'ë': "nl af fr", 'ï': "nl af fr", 'ò': "it ca", 'ì': "it", 'ù': "it fr",
+}
+
+var wordIndex = buildWordIndex()
+
+// buildWordIndex maps each frequent word to the languages using it.
+func buildWordIndex() map[string][]string {
+	index := map[string][]string{}
+	for code, words := range frequentWords {
+		for _, w := range strings.Fields(words) {
+			index[w] = append(index[w], code)
+		}
+	}
+	return index
+}
+
+// Detect identifies the language of text with an offline detector: the
+// writing system decides for scripts used by a single supported language;
+// Latin, Cyrillic and Arabic texts are told apart by characteristic letters
+// and the most frequent words of each language.
+func Detect(text string) Detection {
+	scripts := map[string]int{}
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		scripts[scriptOf(r)]++
+	}
+	if letters == 0 {
+		return Detection{}
+	}
+
+	script, count := "", 0
+	for s, n := range scripts {
+		if n > count || (n == count && s < script) {
+			script, count = s, n
+		}
+	}
+	share := float64(count) / float64(letters)
+
+	switch script {
+	case "Latin":
+		d := detectLatin(text)
+		d.Confidence *= share
+		return d
+	case "Cyrillic":
+		return Detection{Code: cyrillicLanguage(text), Confidence: 0.9 * share}
+	case "Arabic":
+		if strings.ContainsAny(text, "پچژگکی") {
+			return Detection{Code: "fa", Confidence: share}
+		}
+		return Detection{Code: "ar", Confidence: 0.9 * share}
+	case "Han":
+		// Kana anywhere means Japanese, which also uses Han characters.
+		if scripts["Kana"] > 0 {
+			return Detection{Code: "ja", Confidence: float64(count+scripts["Kana"]) / float64(letters)}
+		}
+		return Detection{Code: "zh", Confidence: share}
+	case "Kana":
+		return Detection{Code: "ja", Confidence: float64(count+scripts["Han"]) / float64(letters)}
+	case "":
+		return Detection{}
+	}
+	return Detection{Code: scriptLanguages[script], Confidence: share}
+}
+
+// scriptLanguages maps scripts used by a single supported language to it.
+var scriptLanguages = map[string]string{
+	"Greek":      "el",
+	"Hebrew":     "he",
+	"Devanagari": "hi",
+	"Bengali":    "bn",
+	"Thai":       "th",
+	"Hangul":     "ko",
+}
+
+var scriptTables = []struct {
+	name  string
+	table *unicode.RangeTable
+}{
+	{"Latin", unicode.Latin},
+	{"Cyrillic", unicode.Cyrillic},
+	{"Greek", unicode.Greek},
+	{"Arabic", unicode.Arabic},
+	{"Hebrew", unicode.Hebrew},
+	{"Devanagari", unicode.Devanagari},
+	{"Bengali", unicode.Bengali},
+	{"Thai", unicode.Thai},
+	{"Hangul", unicode.Hangul},
+	{"Kana", unicode.Hiragana},
+	{"Kana", unicode.Katakana},
+	{"Han", unicode.Han},
+}
+
+// scriptOf returns the name of the script of a letter, or "" if it is not
+// one the detector knows.
+func scriptOf(r rune) string {
+	for _, s := range scriptTables {
+		if unicode.Is(s.table, r) {
+			return s.name
+		}
+	}
+	return ""
+}
+
+// cyrillicLanguage tells the Cyrillic-script languages apart by their
+// characteristic letters.
+func cyrillicLanguage(text string) string {
+	lower := strings.ToLower(text)
+	switch {
+	case strings.ContainsAny(lower, "іїєґ"):
+		return "uk"
+	case strings.ContainsAny(lower, "ђјљњћџ"):
+		return "sr"
+	case strings.ContainsRune(lower, 'ъ') && !strings.ContainsAny(lower, "ыэё"):
+		return "bg"
+	}
+	return "ru"
+}
+
+// detectLatin scores the Latin-script languages by the similarity of the
+// text's character trigrams to each language's profile, plus the share of
+// frequent words and characteristic letters. The confidence reflects the
+// margin between the two best candidates.
+func detectLatin(text string) Detection {
+	lower := strings.ToLower(text)
+	words := strings.FieldsFunc(lower, func(r rune) bool { return !unicode.IsLetter(r) })
+	if len(words) < minWords {
+		return Detection{}
+	}
+
+	hits := map[string]float64{}
+	for _, word := range words {
+		if word == "n" {
+			word = "'n" // Afrikaans indefinite article
+		}
+		langs := wordIndex[word]
+		for _, code := range langs {
+			hits[code] += 1 / float64(len(langs))
+		}
+	}
+	for _, r := range lower {
+		if langs, ok := distinctiveLetters[r]; ok {
+			codes := strings.Fields(langs)
+			for _, code := range codes {
+				hits[code] += 0.5 / float64(len(codes))
+			}
+		}
+	}
+
+	textProfile := trigrams(words)
+	type candidate struct {
+		code  string
+		score float64
+	}
+	ranked := make([]candidate, 0, len(profiles))
+	for code, profile := range profiles {
+		score := cosine(textProfile, profile) + hits[code]/float64(len(words))
+		ranked = append(ranked, candidate{code, score})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].code < ranked[j].code
+	})
+
+	best, second := ranked[0], ranked[1]
+	if best.score == 0 {
+		return Detection{}
+	}
+	margin := (best.score - second.score) / best.score
+	return Detection{Code: best.code, Confidence: min(1, margin*confidenceScale)}
+}
+
+// minWords is the number of words below which a Latin-script text is
+// considered too short to identify.
+const minWords = 3
+
+// confidenceScale maps the relative margin between the two best candidates,
+// which rarely exceeds 0.3 for related languages, to a 0..1 confidence.
+const confidenceScale = 3
+
+// samples is a sentence in each Latin-script language (article 1 of the
+// Universal Declaration of Human Rights), from which, together with the
+// frequent words, the trigram profiles are built.
+var samples = map[string]string{
+	"af": "Alle menslike wesens word vry, met gelyke waardigheid en regte, gebore. Hulle het rede en gewete en behoort in die gees van broederskap teenoor mekaar op te tree.",
+	"ca": "Tots els éssers humans neixen lliures i iguals en dignitat i en drets. Són dotats de raó i de consciència, i han de comportar-se fraternalment els uns amb els altres.",
+	"cs": "Všichni lidé rodí se svobodní a sobě rovní co do důstojnosti a práv. Jsou nadáni rozumem a svědomím a mají spolu jednat v duchu bratrství.",
+	"da": "Alle mennesker er født frie og lige i værdighed og rettigheder. De er udstyret med fornuft og samvittighed, og de bør handle mod hverandre i en broderskabets ånd.",
+	"de": "Alle Menschen sind frei und gleich an Würde und Rechten geboren. Sie sind mit Vernunft und Gewissen begabt und sollen einander im Geist der Brüderlichkeit begegnen.",
+	"en": "All human beings are born free and equal in dignity and rights. They are endowed with reason and conscience and should act towards one another in a spirit of brotherhood.",
+	"es": "Todos los seres humanos nacen libres e iguales en dignidad y derechos y, dotados como están de razón y conciencia, deben comportarse fraternalmente los unos con los otros.",
+	"et": "Kõik inimesed sünnivad vabadena ja võrdsetena oma väärikuselt ja õigustelt. Neile on antud mõistus ja südametunnistus ja nende suhtumist üksteisesse peab kandma vendluse vaim.",
+	"fi": "Kaikki ihmiset syntyvät vapaina ja tasavertaisina arvoltaan ja oikeuksiltaan. Heille on annettu järki ja omatunto, ja heidän on toimittava toisiaan kohtaan veljeyden hengessä.",
+	"fr": "Tous les êtres humains naissent libres et égaux en dignité et en droits. Ils sont doués de raison et de conscience et doivent agir les uns envers les autres dans un esprit de fraternité.",
+	"hr": "Sva ljudska bića rađaju se slobodna i jednaka u dostojanstvu i pravima. Ona su obdarena razumom i sviješću pa jedna prema drugima trebaju postupati u duhu bratstva.",
+	"hu": "Minden emberi lény szabadon születik és egyenlő méltósága és joga van. Az emberek, ésszel és lelkiismerettel bírván, egymással szemben testvéri szellemben kell hogy viseltessenek.",
+	"id": "Semua orang dilahirkan merdeka dan mempunyai martabat dan hak-hak yang sama. Mereka dikaruniai akal dan hati nurani dan hendaknya bergaul satu sama lain dalam semangat persaudaraan.",
+	"it": "Tutti gli esseri umani nascono liberi ed eguali in dignità e diritti. Essi sono dotati di ragione e di coscienza e devono agire gli uni verso gli altri in spirito di fratellanza.",
+	"lt": "Visi žmonės gimsta laisvi ir lygūs savo orumu ir teisėmis. Jiems suteiktas protas ir sąžinė ir jie turi elgtis vienas kito atžvilgiu kaip broliai.",
+	"lv": "Visi cilvēki piedzimst brīvi un vienlīdzīgi savā pašcieņā un tiesībās. Viņi ir apveltīti ar saprātu un sirdsapziņu, un viņiem jāizturas citam pret citu brālības garā.",
+	"ms": "Semua manusia dilahirkan bebas dan samarata dari segi kemuliaan dan hak-hak. Mereka mempunyai pemikiran dan perasaan hati dan hendaklah bertindak di antara satu sama lain dengan semangat persaudaraan.",
+	"nl": "Alle mensen worden vrij en gelijk in waardigheid en rechten geboren. Zij zijn begiftigd met verstand en geweten, en behoren zich jegens elkander in een geest van broederschap te gedragen.",
+	"no": "Alle mennesker er født frie og med samme menneskeverd og menneskerettigheter. De er utstyrt med fornuft og samvittighet og bør handle mot hverandre i brorskapets ånd.",
+	"pl": "Wszyscy ludzie rodzą się wolni i równi pod względem swej godności i swych praw. Są oni obdarzeni rozumem i sumieniem i powinni postępować wobec innych w duchu braterstwa.",
+	"pt": "Todos os seres humanos nascem livres e iguais em dignidade e em direitos. Dotados de razão e de consciência, devem agir uns para com os outros em espírito de fraternidade.",
+	"ro": "Toate ființele umane se nasc libere și egale în demnitate și în drepturi. Ele sunt înzestrate cu rațiune și conștiință și trebuie să se comporte unele față de altele în spiritul fraternității.",
+	"sk": "Všetci ľudia sa rodia slobodní a sú si rovní v dôstojnosti aj právach. Sú obdarení rozumom a svedomím a majú spolu jednať v bratskom duchu.",
+	"sl": "Vsi ljudje se rodijo svobodni in imajo enako dostojanstvo in enake pravice. Obdarjeni so z razumom in vestjo in bi morali ravnati drug z drugim kakor bratje.",
+	"sv": "Alla människor är födda fria och lika i värde och rättigheter. De har utrustats med förnuft och samvete och bör handla gentemot varandra i en anda av broderskap.",
+	"tr": "Bütün insanlar hür, haysiyet ve haklar bakımından eşit doğarlar. Akıl ve vicdana sahiptirler ve birbirlerine karşı kardeşlik zihniyeti ile hareket etmelidirler.",
+	"vi": "Tất cả mọi người sinh ra đều được tự do và bình đẳng về nhân phẩm và quyền lợi. Mọi con người đều được tạo hóa ban cho lý trí và lương tâm và cần phải đối xử với nhau trong tình anh em.",
+}
+
+var profiles = buildProfiles()
+
+// buildProfiles computes the trigram profile of each Latin-script language.
+func buildProfiles() map[string]map[string]float64 {
+	profiles := map[string]map[string]float64{}
+	for code, sample := range samples {
+		words := strings.FieldsFunc(strings.ToLower(sample), func(r rune) bool { return !unicode.IsLetter(r) })
+		words = append(words, strings.Fields(frequentWords[code])...)
+		profiles[code] = trigrams(words)
+	}
+	return profiles
+}
+
+// trigrams counts the character trigrams of words, each padded with spaces
+// so that word beginnings and endings count.
+func trigrams(words []string) map[string]float64 {
+	counts := map[string]float64{}
+	for _, w := range words {
+		r := []rune(" " + w + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			counts[string(r[i:i+3])]++
+		}
+	}
+	return counts
+}
+
+// cosine returns the cosine similarity of two trigram profiles.
+func cosine(a, b map[string]float64) float64 {
+	var dot, na, nb float64
+	for k, v := range a {
+		dot += v * b[k]
+		na += v * v
+	}
+	for _, v := range b {
+		nb += v * v
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
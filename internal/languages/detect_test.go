@@ -0,0 +1,62 @@
+package languages
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Le chat est sur la table et il regarde les oiseaux dans le jardin.", "fr"},
+		{"El gato está sobre la mesa y mira los pájaros en el jardín.", "es"},
+		{"O gato está em cima da mesa e olha para os pássaros no jardim.", "pt"},
+		{"Il gatto è sul tavolo e guarda gli uccelli nel giardino.", "it"},
+		{"The cat is on the table and it watches the birds in the garden.", "en"},
+		{"Die Katze sitzt auf dem Tisch und beobachtet die Vögel im Garten.", "de"},
+		{"De kat zit op de tafel en kijkt naar de vogels in de tuin.", "nl"},
+		{"Katten sitter på bordet och tittar på fåglarna i trädgården.", "sv"},
+		{"Kot siedzi na stole i patrzy na ptaki w ogrodzie.", "pl"},
+		{"Pisica stă pe masă și se uită la păsările din grădină.", "ro"},
+		{"Kedi masanın üstünde ve bahçedeki kuşlara bakıyor.", "tr"},
+		{"A macska az asztalon ül, és nézi a madarakat a kertben.", "hu"},
+		{"Con mèo ở trên bàn và nó nhìn những con chim trong vườn.", "vi"},
+		{"Кошка сидит на столе и смотрит на птиц в саду.", "ru"},
+		{"Кішка сидить на столі і дивиться на птахів у саду.", "uk"},
+		{"Η γάτα είναι στο τραπέζι και κοιτάζει τα πουλιά.", "el"},
+		{"החתול יושב על השולחן ומסתכל על הציפורים.", "he"},
+		{"القطة على الطاولة وتنظر إلى الطيور في الحديقة.", "ar"},
+		{"گربه روی میز نشسته و به پرندگان نگاه می‌کند.", "fa"},
+		{"猫はテーブルの上にいて、庭の鳥を見ています。", "ja"},
+		{"猫在桌子上看着花园里的鸟。", "zh"},
+		{"고양이가 탁자 위에서 정원의 새들을 보고 있다.", "ko"},
+		{"बिल्ली मेज़ पर बैठी है और बगीचे में पक्षियों को देख रही है।", "hi"},
+	}
+	for _, tt := range tests {
+		got := Detect(tt.text)
+		if got.Code != tt.want {
+			t.Errorf("Detect(%q) = %s (%.2f), want %s", tt.text, got.Code, got.Confidence, tt.want)
+		}
+		if got.Confidence <= 0 || got.Confidence > 1 {
+			t.Errorf("Detect(%q) confidence %v out of range", tt.text, got.Confidence)
+		}
+	}
+}
+
+func TestDetect_Undetermined(t *testing.T) {
+	for _, text := range []string{"", "1234 — 5678", "Bonjour", "`go test`"} {
+		if got := Detect(text); got.Code != "" {
+			t.Errorf("Detect(%q) = %s, want undetermined", text, got.Code)
+		}
+	}
+}
+
+func TestDetect_ConfidenceReflectsMixedScripts(t *testing.T) {
+	pure := Detect("Кошка сидит на столе и смотрит на птиц в саду.")
+	mixed := Detect("Кошка сидит на столе и смотрит на птиц. The cat sleeps.")
+	if mixed.Code != "ru" {
+		t.Fatalf("expected the majority script to win, got %s", mixed.Code)
+	}
+	if mixed.Confidence >= pure.Confidence {
+		t.Errorf("mixed text should have a lower confidence: %.2f >= %.2f", mixed.Confidence, pure.Confidence)
+	}
+}
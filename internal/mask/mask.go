@@ -0,0 +1,290 @@
+// Package mask protects the parts of a markdown block that must not be
+// translated: code spans, autolinks, link and image destinations, URLs and
+// HTML tags are replaced by placeholder tokens before translation and put
+// back afterwards.
+package mask
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bilingual_pdf/internal/placeholder"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Prefix is the placeholder prefix used for masked markdown.
+const Prefix = 'M'
+
+var (
+	tagPattern = regexp.MustCompile(`<[^<>]+>`)
+	urlPattern = regexp.MustCompile(`\bhttps?://[^\s<>()\[\]]*[^\s<>()\[\].,;:!?'"]`)
+
+	// linkGapPattern matches whitespace between a "]" and a mask token,
+	// which an engine may insert between link text and its masked
+	// destination and so break the link.
+	linkGapPattern = regexp.MustCompile(`\]\s+(\{\{\s*M\s*(\d+)\s*\}\})`)
+)
+
+// span is a byte range of the source to mask.
+type span struct{ start, end int }
+
+// Markdown replaces the untranslatable parts of a markdown fragment with
+// tokens from set and returns the masked text.
+func Markdown(src string, set *placeholder.Set) string {
+	source := []byte(src)
+	doc := goldmark.New().Parser().Parse(text.NewReader(source))
+
+	var spans []span
+	cursor := 0 // end of the last text seen, used to locate nodes without text
+	add := func(s span) {
+		if s.start >= 0 && s.end > s.start && s.end <= len(source) {
+			spans = append(spans, s)
+			cursor = max(cursor, s.end)
+		}
+	}
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Text:
+			cursor = max(cursor, n.Segment.Stop)
+		case *ast.CodeSpan:
+			add(codeSpan(n, source))
+			return ast.WalkSkipChildren, nil
+		case *ast.AutoLink:
+			needle := []byte("<" + string(n.Label(source)) + ">")
+			if i := bytes.Index(source[cursor:], needle); i >= 0 {
+				add(span{cursor + i, cursor + i + len(needle)})
+			}
+		case *ast.RawHTML:
+			if n.Segments.Len() > 0 {
+				add(span{n.Segments.At(0).Start, n.Segments.At(n.Segments.Len() - 1).Stop})
+			}
+		case *ast.Image:
+			// Like link texts, the alt text and the title of an image stay
+			// translatable; only the destination is masked.
+			if end := textEnd(n, source, cursor); end >= 0 {
+				spans = append(spans, imageDestination(source, end, n.Title)...)
+			}
+		case *ast.Link:
+			// The link text stays translatable; only the destination is
+			// masked. The cursor is left alone so that nodes inside the link
+			// text can still be located.
+			if end := textEnd(n, source, cursor); end >= 0 {
+				if d := destination(source, end); d.end > d.start {
+					spans = append(spans, d)
+				}
+			}
+		case *ast.HTMLBlock:
+			for i := 0; i < n.Lines().Len(); i++ {
+				line := n.Lines().At(i)
+				for _, m := range tagPattern.FindAllIndex(line.Value(source), -1) {
+					add(span{line.Start + m[0], line.Start + m[1]})
+				}
+			}
+			if n.HasClosure() {
+				c := n.ClosureLine
+				add(span{c.Start, c.Stop})
+			}
+		case *ast.CodeBlock, *ast.FencedCodeBlock:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, m := range urlPattern.FindAllStringIndex(src, -1) {
+		spans = append(spans, span{m[0], m[1]})
+	}
+	return apply(src, spans, set)
+}
+
+// Restore puts the masked fragments back into a translation and returns the
+// fragments whose token did not come back.
+func Restore(translated string, set *placeholder.Set) (string, []string) {
+	translated = linkGapPattern.ReplaceAllStringFunc(translated, func(gap string) string {
+		m := linkGapPattern.FindStringSubmatch(gap)
+		i, err := strconv.Atoi(m[2])
+		if err != nil || i >= set.Len() {
+			return gap
+		}
+		// Only a link destination, "(url)" or "[ref]", belongs right
+		// after the "]"; a code span or URL keeps its space.
+		if v := set.Value(i); strings.HasPrefix(v, "(") || strings.HasPrefix(v, "[") {
+			return "]" + m[1]
+		}
+		return gap
+	})
+	return set.Restore(translated)
+}
+
+// apply replaces the spans of src with tokens, in order of appearance.
+// Spans overlapping an earlier one (such as a URL inside a masked link
+// destination) are dropped.
+func apply(src string, spans []span, set *placeholder.Set) string {
+	if len(spans) == 0 {
+		return src
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+
+	var buf strings.Builder
+	last := 0
+	for _, s := range spans {
+		if s.start < last {
+			continue
+		}
+		buf.WriteString(src[last:s.start])
+		buf.WriteString(set.Add(src[s.start:s.end]))
+		last = s.end
+	}
+	buf.WriteString(src[last:])
+	return buf.String()
+}
+
+// codeSpan returns the range of a code span including its backticks.
+func codeSpan(n *ast.CodeSpan, source []byte) span {
+	first, ok1 := n.FirstChild().(*ast.Text)
+	last, ok2 := n.LastChild().(*ast.Text)
+	if !ok1 || !ok2 {
+		return span{-1, -1}
+	}
+	start, end := first.Segment.Start, last.Segment.Stop
+	for start > 0 && source[start-1] == ' ' {
+		start--
+	}
+	ticks := 0
+	for start > 0 && source[start-1] == '`' {
+		start--
+		ticks++
+	}
+	for end < len(source) && source[end] == ' ' {
+		end++
+	}
+	for ; ticks > 0 && end < len(source) && source[end] == '`'; ticks-- {
+		end++
+	}
+	return span{start, end}
+}
+
+// image returns the range of a whole image, ![alt](destination).
+func image(n *ast.Image, source []byte, cursor int) span {
+	end := textEnd(n, source, cursor)
+	if end < 0 {
+		return span{-1, -1}
+	}
+	start := bytes.LastIndex(source[:end], []byte("!["))
+	if start < 0 {
+		return span{-1, -1}
+	}
+	return span{start, destination(source, end).end}
+}
+
+// imageDestination returns the ranges to mask of the destination of an
+// image following the "]" at position close. The title is left out:
+// (url "title") gives the ranges of `(url "` and `")`.
+func imageDestination(source []byte, close int, title []byte) []span {
+	d := destination(source, close)
+	if d.end <= d.start {
+		return nil
+	}
+	if len(title) > 0 && source[d.start] == '(' {
+		if i := bytes.LastIndex(source[d.start:d.end], title); i > 0 {
+			t := d.start + i
+			return []span{{d.start, t}, {t + len(title), d.end}}
+		}
+	}
+	return []span{d}
+}
+
+// textEnd returns the position of the "]" closing the text of a link or
+// image, or -1 if it cannot be found.
+func textEnd(n ast.Node, source []byte, cursor int) int {
+	from := cursor
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || c == n {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			from = max(from, c.Segment.Stop)
+		case *ast.Image:
+			// An image inside link text, as in [![badge](badge.svg)](url).
+			from = max(from, image(c, source, from).end)
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	i := bytes.IndexByte(source[from:], ']')
+	if i < 0 {
+		return -1
+	}
+	return from + i
+}
+
+// destination returns the range of the destination following the "]" at
+// position close: an inline "(url "title")" or a reference "[label]".
+func destination(source []byte, close int) span {
+	i := close + 1
+	if i >= len(source) {
+		return span{-1, -1}
+	}
+	switch source[i] {
+	case '(':
+		if end := matchParen(source, i); end > 0 {
+			return span{i, end}
+		}
+	case '[':
+		if j := bytes.IndexByte(source[i:], ']'); j > 0 {
+			return span{i, i + j + 1}
+		}
+	}
+	return span{-1, -1}
+}
+
+// matchParen returns the position after the ")" matching the "(" at open,
+// skipping over <...> destinations, quoted titles and escapes.
+func matchParen(source []byte, open int) int {
+	depth := 0
+	var quote byte
+	for i := open; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && isSpace(source[i-1]):
+			quote = c
+		case c == '<':
+			if j := bytes.IndexByte(source[i:], '>'); j > 0 {
+				i += j
+			}
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
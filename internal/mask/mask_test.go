@@ -0,0 +1,159 @@
+package mask
+
+import (
+	"testing"
+
+	"bilingual_pdf/internal/placeholder"
+)
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		masked string
+		values []string
+	}{
+		{
+			name:   "code span",
+			input:  "Run `go test ./...` before pushing.",
+			masked: "Run {{M0}} before pushing.",
+			values: []string{"`go test ./...`"},
+		},
+		{
+			name:   "double backtick code span",
+			input:  "Write `` a`b `` here.",
+			masked: "Write {{M0}} here.",
+			values: []string{"`` a`b ``"},
+		},
+		{
+			name:   "link keeps its text",
+			input:  "See [the guide](https://example.com/guide \"Guide\") for details.",
+			masked: "See [the guide]{{M0}} for details.",
+			values: []string{"(https://example.com/guide \"Guide\")"},
+		},
+		{
+			name:   "reference link",
+			input:  "See [the guide][guide].\n\n[guide]: https://example.com",
+			masked: "See [the guide]{{M0}}.\n\n[guide]: {{M1}}",
+			values: []string{"[guide]", "https://example.com"},
+		},
+		{
+			name:   "autolink and bare URL",
+			input:  "Visit <https://example.com> or https://example.org/path.",
+			masked: "Visit {{M0}} or {{M1}}.",
+			values: []string{"<https://example.com>", "https://example.org/path"},
+		},
+		{
+			name:   "image keeps its alt text",
+			input:  "A logo: ![Logo](img/logo.png) and ![](empty.png).",
+			masked: "A logo: ![Logo]{{M0}} and ![]{{M1}}.",
+			values: []string{"(img/logo.png)", "(empty.png)"},
+		},
+		{
+			name:   "image keeps its title",
+			input:  "See ![Flow](img/flow.png \"The flow\").",
+			masked: "See ![Flow]{{M0}}The flow{{M1}}.",
+			values: []string{"(img/flow.png \"", "\")"},
+		},
+		{
+			name:   "badge inside a link",
+			input:  "[![Build](badge.svg)](https://ci.example.com) status",
+			masked: "[![Build]{{M0}}]{{M1}} status",
+			values: []string{"(badge.svg)", "(https://ci.example.com)"},
+		},
+		{
+			name:   "code inside link text",
+			input:  "Call [`Parse`](parser.md) first.",
+			masked: "Call [{{M0}}]{{M1}} first.",
+			values: []string{"`Parse`", "(parser.md)"},
+		},
+		{
+			name:   "inline HTML",
+			input:  "Press <kbd>Ctrl</kbd> twice.",
+			masked: "Press {{M0}}Ctrl{{M1}} twice.",
+			values: []string{"<kbd>", "</kbd>"},
+		},
+		{
+			name:   "HTML block",
+			input:  "<div class=\"note\">\n<p>Hello</p>\n</div>",
+			masked: "{{M0}}\n{{M1}}Hello{{M2}}\n{{M3}}",
+			values: []string{"<div class=\"note\">", "<p>", "</p>", "</div>"},
+		},
+		{
+			name:   "list items",
+			input:  "- Use `make`\n- Read [docs](./docs)",
+			masked: "- Use {{M0}}\n- Read [docs]{{M1}}",
+			values: []string{"`make`", "(./docs)"},
+		},
+		{
+			name:   "plain text untouched",
+			input:  "Nothing to protect (really).",
+			masked: "Nothing to protect (really).",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := placeholder.NewSet(Prefix)
+			got := Markdown(tt.input, set)
+			if got != tt.masked {
+				t.Errorf("Markdown(%q) = %q, want %q", tt.input, got, tt.masked)
+			}
+			if set.Len() != len(tt.values) {
+				t.Fatalf("expected %d masked values, got %d", len(tt.values), set.Len())
+			}
+			for i, want := range tt.values {
+				if v := set.Value(i); v != want {
+					t.Errorf("value %d = %q, want %q", i, v, want)
+				}
+			}
+
+			restored, missing := Restore(got, set)
+			if restored != tt.input {
+				t.Errorf("round trip gave %q, want %q", restored, tt.input)
+			}
+			if len(missing) != 0 {
+				t.Errorf("unexpected missing values %q", missing)
+			}
+		})
+	}
+}
+
+func TestRestore_LinkGap(t *testing.T) {
+	set := placeholder.NewSet(Prefix)
+	masked := Markdown("Read [the docs](https://example.com).", set)
+
+	// Engines often put a space between the link text and the token.
+	restored, missing := Restore("Lea [la documentación] {{M0}}.", set)
+	if restored != "Lea [la documentación](https://example.com)." {
+		t.Errorf("unexpected restored text %q (masked %q)", restored, masked)
+	}
+	if len(missing) != 0 {
+		t.Errorf("unexpected missing values %q", missing)
+	}
+}
+
+func TestRestore_BracketBeforeToken(t *testing.T) {
+	for _, tc := range []struct{ src, translated, want string }{
+		{"Le tableau [1] `x` montre", "Le tableau [1] {{M0}} montre", "Le tableau [1] `x` montre"},
+		{"Statut [brouillon] https://example.com/a", "Statut [brouillon] {{M0}}", "Statut [brouillon] https://example.com/a"},
+	} {
+		set := placeholder.NewSet(Prefix)
+		if masked := Markdown(tc.src, set); masked != tc.translated {
+			t.Fatalf("Markdown(%q) = %q, want %q", tc.src, masked, tc.translated)
+		}
+		if restored, _ := Restore(tc.translated, set); restored != tc.want {
+			t.Errorf("Restore(%q) = %q, want %q", tc.translated, restored, tc.want)
+		}
+	}
+}
+
+func TestRestore_Missing(t *testing.T) {
+	set := placeholder.NewSet(Prefix)
+	Markdown("Run `make` now.", set)
+
+	_, missing := Restore("Ejecute ahora.", set)
+	if len(missing) != 1 || missing[0] != "`make`" {
+		t.Errorf("expected `make` to be reported missing, got %q", missing)
+	}
+}
@@ -49,3 +49,38 @@ func TranslationOutputName(inputPath, sourceLang, targetLang string) string {
 	}
 	return filepath.Join(dir, s+"."+targetLang+".md")
 }
+
+// CheckpointName computes the checkpoint filename used to resume an
+// interrupted translation run: the PDF output name with .checkpoint.json.
+func CheckpointName(inputPath, sourceLang, targetLang, explicitOutput string) string {
+	pdf := OutputName(inputPath, sourceLang, targetLang, explicitOutput)
+	return strings.TrimSuffix(pdf, ".pdf") + ".checkpoint.json"
+}
+
+// QAReportName computes the filename of the JSON quality report:
+// the PDF output name with .qa.json.
+func QAReportName(inputPath, sourceLang, targetLang, explicitOutput string) string {
+	pdf := OutputName(inputPath, sourceLang, targetLang, explicitOutput)
+	return strings.TrimSuffix(pdf, ".pdf") + ".qa.json"
+}
+
+// XLIFFName computes the filename of an XLIFF export:
+// the PDF output name with .xlf.
+func XLIFFName(inputPath, sourceLang, targetLang, explicitOutput string) string {
+	pdf := OutputName(inputPath, sourceLang, targetLang, explicitOutput)
+	return strings.TrimSuffix(pdf, ".pdf") + ".xlf"
+}
+
+// TMXName computes the filename of a TMX export:
+// the PDF output name with .tmx.
+func TMXName(inputPath, sourceLang, targetLang, explicitOutput string) string {
+	pdf := OutputName(inputPath, sourceLang, targetLang, explicitOutput)
+	return strings.TrimSuffix(pdf, ".pdf") + ".tmx"
+}
+
+// POName computes the filename of a gettext PO export:
+// the PDF output name with .po.
+func POName(inputPath, sourceLang, targetLang, explicitOutput string) string {
+	pdf := OutputName(inputPath, sourceLang, targetLang, explicitOutput)
+	return strings.TrimSuffix(pdf, ".pdf") + ".po"
+}
@@ -132,3 +132,61 @@ func TestTranslationOutputName(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckpointName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		explicit string
+		want     string
+	}{
+		{name: "basic", input: "doc.md", want: "doc.fr.es.checkpoint.json"},
+		{name: "source suffix dedup", input: "doc.fr.md", want: "doc.fr.es.checkpoint.json"},
+		{name: "explicit output", input: "doc.md", explicit: "out/book.pdf", want: "out/book.checkpoint.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckpointName(tt.input, "fr", "es", tt.explicit)
+			if got != tt.want {
+				t.Errorf("CheckpointName(%q, fr, es, %q) = %q, want %q", tt.input, tt.explicit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQAReportName(t *testing.T) {
+	if got := QAReportName("doc.fr.md", "fr", "es", ""); got != "doc.fr.es.qa.json" {
+		t.Errorf("QAReportName = %q, want doc.fr.es.qa.json", got)
+	}
+	if got := QAReportName("doc.md", "fr", "es", "out/book.pdf"); got != "out/book.qa.json" {
+		t.Errorf("QAReportName with explicit output = %q, want out/book.qa.json", got)
+	}
+}
+
+func TestXLIFFName(t *testing.T) {
+	if got := XLIFFName("doc.fr.md", "fr", "es", ""); got != "doc.fr.es.xlf" {
+		t.Errorf("XLIFFName = %q, want doc.fr.es.xlf", got)
+	}
+	if got := XLIFFName("dir/doc.md", "fr", "es", ""); got != "dir/doc.fr.es.xlf" {
+		t.Errorf("XLIFFName = %q, want dir/doc.fr.es.xlf", got)
+	}
+}
+
+func TestTMXName(t *testing.T) {
+	if got := TMXName("doc.fr.md", "fr", "es", ""); got != "doc.fr.es.tmx" {
+		t.Errorf("TMXName = %q, want doc.fr.es.tmx", got)
+	}
+	if got := TMXName("doc.md", "fr", "es", "out/bi.pdf"); got != "out/bi.tmx" {
+		t.Errorf("TMXName = %q, want out/bi.tmx", got)
+	}
+}
+
+func TestPOName(t *testing.T) {
+	if got := POName("doc.fr.md", "fr", "es", ""); got != "doc.fr.es.po" {
+		t.Errorf("POName = %q, want doc.fr.es.po", got)
+	}
+	if got := POName("dir/doc.md", "fr", "es", ""); got != "dir/doc.fr.es.po" {
+		t.Errorf("POName = %q, want dir/doc.fr.es.po", got)
+	}
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Meta holds the YAML front matter of a document: its metadata and the
+// build settings it carries.
+type Meta struct {
+	Title  string `yaml:"title"`
+	Author string `yaml:"author"`
+	Date   string `yaml:"date"`
+
+	Source   string `yaml:"source"`    // source language code
+	Target   string `yaml:"target"`    // target language code
+	FontSize string `yaml:"font-size"` // font size preset
+	Engine   string `yaml:"engine"`    // translation engine
+	Glossary string `yaml:"glossary"`  // glossary file, relative to the document
+	PageSize string `yaml:"page-size"` // page size of the PDF
+}
+
+// FrontMatter splits the YAML front matter, between "---" lines at the top
+// of the file, off a markdown source. It returns nil metadata if there is
+// none, and the source with the front matter blanked out, so that the lines
+// of the blocks still count from the top of the file. A "---" block that
+// is not a non-empty YAML mapping, as in a document starting with a
+// thematic break, is left to the markdown.
+func FrontMatter(source []byte) (*Meta, []byte, error) {
+	body, end, ok := frontMatterRange(source)
+	if !ok {
+		return nil, source, nil
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(body, &node); err != nil {
+		return nil, source, nil
+	}
+	// An empty block, or one of only comments such as a "# heading"
+	// between thematic breaks, is not front matter.
+	if len(node.Content) == 0 || node.Content[0].Kind != yaml.MappingNode || len(node.Content[0].Content) == 0 {
+		return nil, source, nil
+	}
+	meta := &Meta{}
+	if err := node.Content[0].Decode(meta); err != nil {
+		return nil, nil, fmt.Errorf("front matter: %w", err)
+	}
+	blanked := append(bytes.Repeat([]byte("\n"), bytes.Count(source[:end], []byte("\n"))), source[end:]...)
+	return meta, blanked, nil
+}
+
+// frontMatterRange returns the YAML between the opening "---" line and the
+// closing "---" or "..." line, and the end of the closing line.
+func frontMatterRange(source []byte) (body []byte, end int, ok bool) {
+	src := bytes.TrimPrefix(source, []byte("\xef\xbb\xbf"))
+	offset := len(source) - len(src)
+	line, rest, found := bytes.Cut(src, []byte("\n"))
+	if !found || string(bytes.TrimRight(line, " \t\r")) != "---" {
+		return nil, 0, false
+	}
+	start := offset + len(line) + 1
+	pos := start
+	for len(rest) > 0 {
+		line, next, found := bytes.Cut(rest, []byte("\n"))
+		lineEnd := pos + len(line)
+		if found {
+			lineEnd++
+		}
+		switch string(bytes.TrimRight(line, " \t\r")) {
+		case "---", "...":
+			return source[start:pos], lineEnd, true
+		}
+		pos, rest = lineEnd, next
+	}
+	return nil, 0, false
+}
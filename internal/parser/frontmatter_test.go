@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFrontMatter(t *testing.T) {
+	input := "---\ntitle: Le fromage\nauthor: Marie\ndate: 2024-05-01\nsource: fr\npage-size: letter\n---\n# Titre\n\nTexte.\n"
+	meta, body, err := FrontMatter([]byte(input))
+	if err != nil {
+		t.Fatalf("FrontMatter failed: %v", err)
+	}
+	want := Meta{Title: "Le fromage", Author: "Marie", Date: "2024-05-01", Source: "fr", PageSize: "letter"}
+	if meta == nil || *meta != want {
+		t.Fatalf("meta = %+v, want %+v", meta, want)
+	}
+
+	blocks, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(blocks) != 2 || blocks[0].Kind != BlockHeading {
+		t.Fatalf("the front matter should not give blocks, got %+v", blocks)
+	}
+	// Lines still count from the top of the file.
+	if blocks[0].Line != 8 || blocks[1].Line != 10 {
+		t.Errorf("lines = %d, %d, want 8, 10", blocks[0].Line, blocks[1].Line)
+	}
+	if want := strings.Repeat("\n", 7) + "# Titre\n\nTexte.\n"; string(body) != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestFrontMatter_NotFrontMatter(t *testing.T) {
+	for _, input := range []string{
+		"# Title\n\n---\nkey: value\n---\n",
+		"---\n\nA paragraph between rules.\n\n---\n",
+		"---\n# A heading\n---\n",
+		"---\nno closing line\n",
+		"---\n* one\n* two\n---\n\nText.\n",
+		"---\nNote: this is a sentence: with colons\n---\n",
+		"---\n---\n",
+		"---\n\n---\nText.\n",
+		"---\n{}\n---\n",
+	} {
+		meta, body, err := FrontMatter([]byte(input))
+		if err != nil || meta != nil || string(body) != input {
+			t.Errorf("FrontMatter(%q) = %+v, %q, %v; want no front matter", input, meta, body, err)
+		}
+	}
+}
+
+func TestFrontMatter_Invalid(t *testing.T) {
+	if _, err := Parse([]byte("---\ntitle: [a, b]\n---\nText.\n")); err == nil {
+		t.Error("expected an error for a title that is not a string")
+	}
+}
+
+func TestParse_ThematicBreakFirst(t *testing.T) {
+	blocks, err := Parse([]byte("---\n* one\n* two\n---\n\nText.\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(blocks) != 4 || blocks[0].Kind != BlockThematicBreak || blocks[1].Kind != BlockList {
+		t.Errorf("expected a rule, a list, a rule and a paragraph, got %+v", blocks)
+	}
+}
+
+func TestParse_EmptyFrontMatter(t *testing.T) {
+	blocks, err := Parse([]byte("---\n---\n\nText.\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(blocks) != 3 || blocks[0].Kind != BlockThematicBreak || blocks[1].Kind != BlockThematicBreak {
+		t.Errorf("expected two rules and a paragraph, got %+v", blocks)
+	}
+}
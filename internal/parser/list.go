@@ -0,0 +1,217 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// List holds the structure of a list block, so that its paragraphs can be
+// translated one by one and put back at their depth.
+type List struct {
+	Ordered bool
+	Start   int  // number of the first item of an ordered list
+	Marker  byte // '-', '*' or '+' for a bullet list, '.' or ')' for an ordered list
+	Tight   bool // no blank lines between items and their blocks
+	Items   []ListItem
+}
+
+// ListItem is an item of a list: its blocks, in order.
+type ListItem struct {
+	Blocks []NestedBlock
+}
+
+// NestedBlock is a block of a list item or a blockquote: a paragraph or
+// heading of inline markdown, a nested list or quote, or markdown kept as
+// it is, such as a code block.
+type NestedBlock struct {
+	Prefix   string // syntax before Text, as in "## " for a heading
+	Text     string // inline markdown to translate
+	List     *List  // nested list
+	Quote    *Quote // nested blockquote
+	Verbatim string // markdown that is not translated
+}
+
+// Texts returns the inline markdown of the paragraphs of the list, nested
+// blocks included, in reading order.
+func (l *List) Texts() []string {
+	var texts []string
+	for _, item := range l.Items {
+		texts = append(texts, blocksTexts(item.Blocks)...)
+	}
+	return texts
+}
+
+// WithTexts returns a copy of the list with the texts of its paragraphs
+// replaced, in reading order, by texts. Missing texts are left empty.
+func (l *List) WithTexts(texts []string) *List {
+	k := 0
+	return l.withTexts(texts, &k)
+}
+
+func (l *List) withTexts(texts []string, k *int) *List {
+	result := *l
+	result.Items = make([]ListItem, len(l.Items))
+	for i, item := range l.Items {
+		result.Items[i].Blocks = blocksWithTexts(item.Blocks, texts, k)
+	}
+	return &result
+}
+
+// Markdown returns the list in markdown, nested blocks indented under
+// their item.
+func (l *List) Markdown() string {
+	var buf strings.Builder
+	n := l.Start
+	for i, item := range l.Items {
+		if i > 0 && !l.Tight {
+			buf.WriteString("\n")
+		}
+		marker := string(l.Marker) + " "
+		if l.Ordered {
+			marker = fmt.Sprintf("%d%c ", n, l.Marker)
+			n++
+		}
+		if len(item.Blocks) == 0 {
+			buf.WriteString(strings.TrimRight(marker, " ") + "\n")
+			continue
+		}
+		indent := strings.Repeat(" ", len(marker))
+		for j, b := range item.Blocks {
+			if j > 0 && !l.Tight {
+				buf.WriteString("\n")
+			}
+			for k, line := range strings.Split(strings.TrimRight(b.markdown(), "\n"), "\n") {
+				switch {
+				case j == 0 && k == 0:
+					buf.WriteString(marker)
+				case line != "":
+					buf.WriteString(indent)
+				}
+				buf.WriteString(line + "\n")
+			}
+		}
+	}
+	return buf.String()
+}
+
+// markdown returns the block in markdown, without the indentation or the
+// quote markers of its container.
+func (b NestedBlock) markdown() string {
+	switch {
+	case b.List != nil:
+		return b.List.Markdown()
+	case b.Quote != nil:
+		return b.Quote.Markdown()
+	case b.Verbatim != "":
+		return b.Verbatim
+	}
+	return b.Prefix + b.Text
+}
+
+// blocksTexts returns the texts to translate of nested blocks, in reading
+// order.
+func blocksTexts(blocks []NestedBlock) []string {
+	var texts []string
+	for _, b := range blocks {
+		switch {
+		case b.List != nil:
+			texts = append(texts, b.List.Texts()...)
+		case b.Quote != nil:
+			texts = append(texts, b.Quote.Texts()...)
+		case b.Verbatim == "":
+			texts = append(texts, b.Text)
+		}
+	}
+	return texts
+}
+
+// blocksWithTexts returns a copy of nested blocks with their texts
+// replaced by texts from index *k on, advancing *k.
+func blocksWithTexts(blocks []NestedBlock, texts []string, k *int) []NestedBlock {
+	result := make([]NestedBlock, len(blocks))
+	for j, b := range blocks {
+		switch {
+		case b.List != nil:
+			b.List = b.List.withTexts(texts, k)
+		case b.Quote != nil:
+			b.Quote = b.Quote.withTexts(texts, k)
+		case b.Verbatim == "":
+			b.Text = ""
+			if *k < len(texts) {
+				b.Text = texts[*k]
+			}
+			*k++
+		}
+		result[j] = b
+	}
+	return result
+}
+
+// extractListModel reads the structure of a list node, and the plain text
+// of its paragraphs, one per line.
+func extractListModel(list *ast.List, source []byte) (l *List, text []string) {
+	l = &List{
+		Ordered: list.IsOrdered(),
+		Start:   list.Start,
+		Marker:  list.Marker,
+		Tight:   list.IsTight,
+	}
+	for child := list.FirstChild(); child != nil; child = child.NextSibling() {
+		if _, ok := child.(*ast.ListItem); !ok {
+			continue
+		}
+		blocks, itemText := extractNestedBlocks(child, source)
+		l.Items = append(l.Items, ListItem{Blocks: blocks})
+		text = append(text, itemText...)
+	}
+	return l, text
+}
+
+// extractNestedBlocks reads the blocks of a list item or blockquote node,
+// and the plain text of its paragraphs, one per line.
+func extractNestedBlocks(container ast.Node, source []byte) (blocks []NestedBlock, text []string) {
+	for c := container.FirstChild(); c != nil; c = c.NextSibling() {
+		var b NestedBlock
+		switch n := c.(type) {
+		case *ast.Paragraph, *ast.TextBlock:
+			b.Text = inlineMarkdown(n, source)
+			text = append(text, collectText(n, source))
+		case *ast.Heading:
+			b.Prefix = strings.Repeat("#", n.Level) + " "
+			b.Text = strings.ReplaceAll(inlineMarkdown(n, source), "\n", " ")
+			text = append(text, collectText(n, source))
+		case *ast.Blockquote:
+			var nested []string
+			b.Quote, nested = extractQuoteModel(n, source)
+			text = append(text, nested...)
+		case *ast.List:
+			var nested []string
+			b.List, nested = extractListModel(n, source)
+			text = append(text, nested...)
+		default:
+			if nb := extractBlock(c, source); nb != nil {
+				b.Verbatim = strings.TrimRight(nb.Raw, "\n")
+			} else {
+				b.Verbatim = strings.TrimRight(extractLines(c, source), "\n")
+			}
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, text
+}
+
+// inlineMarkdown returns the source lines of a paragraph or heading,
+// without their indentation. Trailing spaces are kept on all but the last
+// line, since two of them mark a hard line break.
+func inlineMarkdown(node ast.Node, source []byte) string {
+	lines := node.Lines()
+	parts := make([]string, 0, lines.Len())
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		line := strings.TrimRight(string(seg.Value(source)), "\r\n")
+		parts = append(parts, strings.TrimLeft(line, " \t"))
+	}
+	return strings.TrimRight(strings.Join(parts, "\n"), " \t")
+}
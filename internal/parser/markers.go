@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// blockMarkerPattern matches an HTML comment that marks the next block:
+	// <!-- notranslate --> or <!-- translate: fixed translation -->.
+	blockMarkerPattern = regexp.MustCompile(`(?s)^<!--\s*(?:(notranslate)|translate:(.*?))\s*-->$`)
+
+	// phrasePattern matches a marked phrase: [text]{.notranslate} or
+	// [text]{translation="fixed translation"}.
+	phrasePattern = regexp.MustCompile(`\[([^\[\]]*)\]\{(?:\.notranslate|translation="([^"]*)")\}`)
+
+	codeSpanPattern = regexp.MustCompile("(`+)[^`]+?(`+)")
+)
+
+// blockMarker reports whether the raw content of an HTML block is a marker
+// comment, and applies it to the block that follows.
+func blockMarker(raw string) (apply func(*Block), ok bool) {
+	m := blockMarkerPattern.FindStringSubmatch(strings.TrimSpace(raw))
+	if m == nil {
+		return nil, false
+	}
+	if m[1] != "" {
+		return func(b *Block) { b.NoTranslate = true }, true
+	}
+	translation := strings.TrimSpace(m[2])
+	return func(b *Block) { b.Translation = translation }, true
+}
+
+// Phrase is a phrase marked in inline markdown: [text]{.notranslate} to
+// copy it into the translation as is, or [text]{translation="..."} to give
+// its translation.
+type Phrase struct {
+	Start, End  int    // byte range of the marked phrase, attribute included
+	Text        string // the phrase
+	Translation string // the fixed translation, "" to keep Text
+}
+
+// Phrases returns the marked phrases of inline markdown, outside of code
+// spans.
+func Phrases(md string) []Phrase {
+	code := codeSpanPattern.FindAllStringSubmatchIndex(md, -1)
+	inCode := func(start int) bool {
+		for _, c := range code {
+			// Only spans closed by a backtick run of the same length.
+			if c[3]-c[2] == c[5]-c[4] && start >= c[0] && start < c[1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var phrases []Phrase
+	for _, m := range phrasePattern.FindAllStringSubmatchIndex(md, -1) {
+		if inCode(m[0]) {
+			continue
+		}
+		p := Phrase{Start: m[0], End: m[1], Text: md[m[2]:m[3]]}
+		if m[4] >= 0 {
+			p.Translation = md[m[4]:m[5]]
+		}
+		phrases = append(phrases, p)
+	}
+	return phrases
+}
+
+// StripPhrases replaces the marked phrases of inline markdown with their
+// text, as they are rendered in the source column.
+func StripPhrases(md string) string {
+	return ReplacePhrases(md, func(p Phrase) string { return p.Text })
+}
+
+// ReplacePhrases replaces each marked phrase of inline markdown with the
+// result of repl.
+func ReplacePhrases(md string, repl func(Phrase) string) string {
+	phrases := Phrases(md)
+	if len(phrases) == 0 {
+		return md
+	}
+	var buf strings.Builder
+	last := 0
+	for _, p := range phrases {
+		buf.WriteString(md[last:p.Start])
+		buf.WriteString(repl(p))
+		last = p.End
+	}
+	buf.WriteString(md[last:])
+	return buf.String()
+}
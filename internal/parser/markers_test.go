@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse_BlockMarkers(t *testing.T) {
+	input := "<!-- notranslate -->\nKeep me.\n\n<!-- translate: Hola. -->\nBonjour.\n\nPlain.\n\n<!-- a comment -->\n"
+	blocks, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(blocks) != 4 {
+		t.Fatalf("expected 4 blocks, got %d", len(blocks))
+	}
+	if !blocks[0].NoTranslate || blocks[0].Text != "Keep me." {
+		t.Errorf("block 0 should be marked notranslate, got %+v", blocks[0])
+	}
+	if blocks[1].Translation != "Hola." || blocks[1].NoTranslate {
+		t.Errorf("block 1 should have the fixed translation, got %+v", blocks[1])
+	}
+	if blocks[2].NoTranslate || blocks[2].Translation != "" {
+		t.Errorf("block 2 should not be marked, got %+v", blocks[2])
+	}
+	if blocks[3].Kind != BlockHTML {
+		t.Errorf("other comments should stay HTML blocks, got %v", blocks[3].Kind)
+	}
+}
+
+func TestPhrases(t *testing.T) {
+	md := "Use [Acme]{.notranslate} and [chat]{translation=\"gato\"}, not `[x]{.notranslate}`."
+	phrases := Phrases(md)
+	if len(phrases) != 2 {
+		t.Fatalf("expected 2 phrases, got %d", len(phrases))
+	}
+	if phrases[0].Text != "Acme" || phrases[0].Translation != "" {
+		t.Errorf("phrase 0 = %+v", phrases[0])
+	}
+	if phrases[1].Text != "chat" || phrases[1].Translation != "gato" {
+		t.Errorf("phrase 1 = %+v", phrases[1])
+	}
+	if got, want := StripPhrases(md), "Use Acme and chat, not `[x]{.notranslate}`."; got != want {
+		t.Errorf("StripPhrases = %q, want %q", got, want)
+	}
+}
+
+func TestParse_PhraseRendering(t *testing.T) {
+	blocks, err := Parse([]byte("Hello [Acme]{.notranslate}.\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if strings.Contains(blocks[0].HTML, "notranslate") || !strings.Contains(blocks[0].HTML, "Hello Acme.") {
+		t.Errorf("the attribute should not be rendered, got %q", blocks[0].HTML)
+	}
+	if !strings.Contains(blocks[0].Raw, "[Acme]{.notranslate}") {
+		t.Errorf("Raw should keep the marker, got %q", blocks[0].Raw)
+	}
+}
+
+func TestParse_PhraseText(t *testing.T) {
+	source := "Keep [Acme]{.notranslate} here and [x]{translation=\"y\"}.\n\n- [**Acme**]{.notranslate} item\n"
+	blocks, err := Parse([]byte(source))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := []string{"Keep Acme here and x.", "Acme item"}
+	if len(blocks) != len(want) {
+		t.Fatalf("expected %d blocks, got %d", len(want), len(blocks))
+	}
+	for i, b := range blocks {
+		if b.Text != want[i] {
+			t.Errorf("block %d: Text = %q, want %q", i, b.Text, want[i])
+		}
+	}
+}
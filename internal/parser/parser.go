@@ -2,11 +2,12 @@ package parser
 
 import (
 	"bytes"
-	"fmt"
 	"strings"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
 	"github.com/yuin/goldmark/text"
 )
 
@@ -21,6 +22,7 @@ const (
 	BlockBlockquote
 	BlockThematicBreak
 	BlockHTML
+	BlockTable
 )
 
 func (k BlockKind) String() string {
@@ -39,6 +41,8 @@ func (k BlockKind) String() string {
 		return "ThematicBreak"
 	case BlockHTML:
 		return "HTML"
+	case BlockTable:
+		return "Table"
 	default:
 		return "Unknown"
 	}
@@ -51,29 +55,60 @@ type Block struct {
 	Raw   string // reconstructed markdown text (includes syntax like #, -, >, etc.)
 	HTML  string // rendered HTML fragment for this block
 	Text  string // plain text content (for translation)
+	Line  int    // line of the source where the block starts (1-based), 0 if unknown
+
+	NoTranslate bool   // marked <!-- notranslate -->: copied into the translation as is
+	Translation string // fixed translation of a block marked <!-- translate: ... -->, "" if none
+
+	Table *Table // cells of a table block, nil for other blocks
+	List  *List  // structure of a list block, nil for other blocks
+	Quote *Quote // structure of a blockquote, nil for other blocks
 }
 
 // Parse reads markdown source bytes and returns an ordered slice of Blocks.
+// A YAML front matter is skipped; see FrontMatter.
 func Parse(source []byte) ([]Block, error) {
-	md := goldmark.New()
+	_, source, err := FrontMatter(source)
+	if err != nil {
+		return nil, err
+	}
+	md := goldmark.New(goldmark.WithExtensions(extension.Table))
 	reader := text.NewReader(source)
 	doc := md.Parser().Parse(reader)
 
 	var blocks []Block
+	var mark func(*Block) // marker comment for the next block
 
 	for child := doc.FirstChild(); child != nil; child = child.NextSibling() {
 		block := extractBlock(child, source)
 		if block == nil {
 			continue
 		}
+		if block.Kind == BlockHTML {
+			if apply, ok := blockMarker(block.Raw); ok {
+				mark = apply
+				continue
+			}
+		}
+		if mark != nil {
+			mark(block)
+			mark = nil
+		}
+		block.Line = startLine(child, source)
 
 		// Render HTML: for HTML blocks the raw content is already HTML;
-		// for everything else, convert the reconstructed markdown.
+		// for everything else, convert the reconstructed markdown, without
+		// the attributes of marked phrases. The plain text loses them too.
 		if block.Kind == BlockHTML {
 			block.HTML = block.Raw
 		} else {
+			raw := block.Raw
+			if block.Kind != BlockCodeBlock {
+				raw = StripPhrases(raw)
+				block.Text = StripPhrases(block.Text)
+			}
 			var buf bytes.Buffer
-			if err := md.Convert([]byte(block.Raw), &buf); err != nil {
+			if err := md.Convert([]byte(raw), &buf); err != nil {
 				return nil, err
 			}
 			block.HTML = buf.String()
@@ -94,7 +129,8 @@ func extractBlock(node ast.Node, source []byte) *Block {
 		b.Kind = BlockHeading
 		b.Level = n.Level
 		b.Text = collectText(node, source)
-		b.Raw = strings.Repeat("#", n.Level) + " " + b.Text
+		// A setext heading may span several lines.
+		b.Raw = strings.Repeat("#", n.Level) + " " + strings.ReplaceAll(inlineMarkdown(n, source), "\n", " ")
 
 	case *ast.Paragraph:
 		b.Kind = BlockParagraph
@@ -103,7 +139,10 @@ func extractBlock(node ast.Node, source []byte) *Block {
 
 	case *ast.List:
 		b.Kind = BlockList
-		b.Text, b.Raw = extractList(n, source)
+		var text []string
+		b.List, text = extractListModel(n, source)
+		b.Text = strings.Join(text, "\n")
+		b.Raw = b.List.Markdown()
 
 	case *ast.FencedCodeBlock:
 		b.Kind = BlockCodeBlock
@@ -129,7 +168,10 @@ func extractBlock(node ast.Node, source []byte) *Block {
 
 	case *ast.Blockquote:
 		b.Kind = BlockBlockquote
-		b.Text, b.Raw = extractBlockquote(n, source)
+		var text []string
+		b.Quote, text = extractQuoteModel(n, source)
+		b.Text = strings.Join(text, "\n")
+		b.Raw = b.Quote.Markdown()
 
 	case *ast.ThematicBreak:
 		b.Kind = BlockThematicBreak
@@ -141,6 +183,11 @@ func extractBlock(node ast.Node, source []byte) *Block {
 		b.Text = extractLines(node, source)
 		b.Raw = b.Text
 
+	case *east.Table:
+		b.Kind = BlockTable
+		b.Text, b.Table = extractTable(n, source)
+		b.Raw = b.Table.Markdown()
+
 	default:
 		return nil
 	}
@@ -148,6 +195,30 @@ func extractBlock(node ast.Node, source []byte) *Block {
 	return b
 }
 
+// startLine returns the 1-based line of the first source text of a node
+// (the opening fence of a fenced code block), or 0 if it has none, as with
+// thematic breaks.
+func startLine(node ast.Node, source []byte) int {
+	start := -1
+	if n, ok := node.(*ast.FencedCodeBlock); ok && n.Info != nil {
+		start = n.Info.Segment.Start
+	}
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if start >= 0 {
+			return ast.WalkStop, nil
+		}
+		if entering && n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+			start = n.Lines().At(0).Start
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	if start < 0 {
+		return 0
+	}
+	return bytes.Count(source[:start], []byte("\n")) + 1
+}
+
 // collectText extracts plain text from inline children of a node.
 func collectText(node ast.Node, source []byte) string {
 	var buf bytes.Buffer
@@ -201,63 +272,16 @@ func extractLines(node ast.Node, source []byte) string {
 	return buf.String()
 }
 
-// extractCodeContent gets the code content from a code block node.
+// extractCodeContent gets the code content from a code block node, with
+// the indentation that the lines of a nested code block keep beyond that
+// of their container.
 func extractCodeContent(node ast.Node, source []byte) string {
-	return extractLines(node, source)
-}
-
-// extractList extracts text and raw markdown from a list node.
-func extractList(list *ast.List, source []byte) (text, raw string) {
-	var textBuf, rawBuf strings.Builder
-	idx := 1
-
-	for child := list.FirstChild(); child != nil; child = child.NextSibling() {
-		if _, ok := child.(*ast.ListItem); !ok {
-			continue
-		}
-		itemText := collectText(child, source)
-		itemContent := extractListItemContent(child, source)
-		textBuf.WriteString(itemText)
-		textBuf.WriteString("\n")
-
-		if list.IsOrdered() {
-			rawBuf.WriteString(fmt.Sprintf("%d. %s\n", idx, itemContent))
-			idx++
-		} else {
-			rawBuf.WriteString("- " + itemContent + "\n")
-		}
-	}
-
-	return strings.TrimRight(textBuf.String(), "\n"), rawBuf.String()
-}
-
-// extractListItemContent gets the original source content from a list item's
-// children, preserving inline markdown syntax (links, emphasis, etc.).
-func extractListItemContent(item ast.Node, source []byte) string {
 	var buf bytes.Buffer
-	for child := item.FirstChild(); child != nil; child = child.NextSibling() {
-		lines := child.Lines()
-		for i := 0; i < lines.Len(); i++ {
-			seg := lines.At(i)
-			buf.Write(seg.Value(source))
-		}
-	}
-	return strings.TrimRight(buf.String(), "\n")
-}
-
-// extractBlockquote extracts text and raw markdown from a blockquote node.
-func extractBlockquote(bq *ast.Blockquote, source []byte) (text, raw string) {
-	var textBuf, rawBuf strings.Builder
-
-	for child := bq.FirstChild(); child != nil; child = child.NextSibling() {
-		childText := collectText(child, source)
-		textBuf.WriteString(childText)
-		textBuf.WriteString("\n")
-
-		for _, line := range strings.Split(childText, "\n") {
-			rawBuf.WriteString("> " + line + "\n")
-		}
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		buf.WriteString(strings.Repeat(" ", seg.Padding))
+		buf.Write(seg.Value(source))
 	}
-
-	return strings.TrimRight(textBuf.String(), "\n"), rawBuf.String()
+	return buf.String()
 }
@@ -182,6 +182,70 @@ func TestParse_HTMLBlock(t *testing.T) {
 	}
 }
 
+func TestParse_HeadingInlineMarkup(t *testing.T) {
+	blocks, err := Parse([]byte("## Le *bon* [fromage](https://example.com) et `brie` ##\n\nSetext\n*titre*\n======\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(blocks))
+	}
+	if want := "## Le *bon* [fromage](https://example.com) et `brie`"; blocks[0].Raw != want {
+		t.Errorf("Raw = %q, want %q", blocks[0].Raw, want)
+	}
+	if blocks[0].Text != "Le bon fromage et brie" {
+		t.Errorf("Text = %q", blocks[0].Text)
+	}
+	if !strings.Contains(blocks[0].HTML, `<em>bon</em> <a href="https://example.com">fromage</a> et <code>brie</code>`) {
+		t.Errorf("heading HTML should keep the inline markup, got %q", blocks[0].HTML)
+	}
+	if blocks[1].Raw != "# Setext *titre*" || !strings.Contains(blocks[1].HTML, "<h1>Setext <em>titre</em></h1>") {
+		t.Errorf("setext heading = %q, %q", blocks[1].Raw, blocks[1].HTML)
+	}
+}
+
+func TestParse_NestedBlockquote(t *testing.T) {
+	input := "> Un **avis** :\n>\n> - pain\n> - vin\n>\n> ```\n> code\n> ```\n>\n> > Citation *imbriquée*.\n"
+	blocks, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(blocks) != 1 || blocks[0].Quote == nil {
+		t.Fatalf("expected one quote block, got %+v", blocks)
+	}
+	b := blocks[0]
+	if b.Raw != input {
+		t.Errorf("Raw = %q, want %q", b.Raw, input)
+	}
+	want := []string{"Un **avis** :", "pain", "vin", "Citation *imbriquée*."}
+	if got := b.Quote.Texts(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Texts = %q, want %q", got, want)
+	}
+	for _, want := range []string{"<strong>avis</strong>", "<li>pain</li>", "<pre><code>code\n</code></pre>", "<blockquote>\n<p>Citation <em>imbriquée</em>.</p>\n</blockquote>\n</blockquote>"} {
+		if !strings.Contains(b.HTML, want) {
+			t.Errorf("HTML should contain %q, got %q", want, b.HTML)
+		}
+	}
+}
+
+func TestParse_HardBreakInBlockquote(t *testing.T) {
+	input := "> a  \n> b\n"
+	blocks, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(blocks) != 1 || blocks[0].Quote == nil {
+		t.Fatalf("expected one quote block, got %+v", blocks)
+	}
+	b := blocks[0]
+	if b.Raw != input {
+		t.Errorf("Raw = %q, want %q", b.Raw, input)
+	}
+	if !strings.Contains(b.HTML, "<p>a<br>\nb</p>") {
+		t.Errorf("hard break in a quote should render as <br>, got %q", b.HTML)
+	}
+}
+
 func TestParse_LinkInParagraph(t *testing.T) {
 	source := []byte("Visitez [Google](https://google.com) pour chercher.\n")
 
@@ -277,6 +341,86 @@ func TestParse_ListRoundTrip(t *testing.T) {
 	}
 }
 
+func TestParse_OrderedListStart(t *testing.T) {
+	blocks, err := Parse([]byte("3. trois\n4. quatre\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(blocks) != 1 {
+		t.Fatalf("expected 1 block, got %d", len(blocks))
+	}
+	if blocks[0].Raw != "3. trois\n4. quatre\n" {
+		t.Errorf("list should keep its first number, got %q", blocks[0].Raw)
+	}
+	if !strings.Contains(blocks[0].HTML, `<ol start="3">`) {
+		t.Errorf("list HTML should start at 3, got %q", blocks[0].HTML)
+	}
+}
+
+func TestParse_NestedList(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		texts []string
+		html  []string
+	}{
+		{
+			name:  "nested bullets",
+			input: "- pain\n  - baguette\n    - tradition\n- fromage\n",
+			texts: []string{"pain", "baguette", "tradition", "fromage"},
+			html:  []string{"<li>pain\n<ul>\n<li>baguette\n<ul>\n<li>tradition</li>", "<li>fromage</li>"},
+		},
+		{
+			name:  "loose items with paragraphs and code",
+			input: "3. Installer\n\n   Suivre le guide.\n\n4. Lancer\n\n   ```sh\n   make\n     run\n   ```\n",
+			texts: []string{"Installer", "Suivre le guide.", "Lancer"},
+			html:  []string{`<ol start="3">`, "<p>Installer</p>\n<p>Suivre le guide.</p>", "<pre><code class=\"language-sh\">make\n  run\n</code></pre>"},
+		},
+		{
+			name:  "ordered list in a bullet list",
+			input: "* un\n  1) a\n  2) b\n* deux\n",
+			texts: []string{"un", "a", "b", "deux"},
+			html:  []string{"<li>un\n<ol>\n<li>a</li>\n<li>b</li>\n</ol>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks, err := Parse([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if len(blocks) != 1 || blocks[0].List == nil {
+				t.Fatalf("expected one list block, got %+v", blocks)
+			}
+			b := blocks[0]
+			if b.Raw != tt.input {
+				t.Errorf("Raw = %q, want %q", b.Raw, tt.input)
+			}
+			if got := b.List.Texts(); strings.Join(got, "|") != strings.Join(tt.texts, "|") {
+				t.Errorf("Texts = %q, want %q", got, tt.texts)
+			}
+			for _, want := range tt.html {
+				if !strings.Contains(b.HTML, want) {
+					t.Errorf("HTML should contain %q, got %q", want, b.HTML)
+				}
+			}
+
+			// Replacing the texts keeps the structure.
+			upper := b.List.Texts()
+			for i := range upper {
+				upper[i] = strings.ToUpper(upper[i])
+			}
+			again, err := Parse([]byte(b.List.WithTexts(upper).Markdown()))
+			if err != nil || len(again) != 1 {
+				t.Fatalf("translated list should parse into one block, got %+v", again)
+			}
+			if got := strings.ToLower(again[0].HTML); got != strings.ToLower(b.HTML) {
+				t.Errorf("translated list HTML = %q, want the structure of %q", again[0].HTML, b.HTML)
+			}
+		})
+	}
+}
+
 func TestParse_LinkInListItem(t *testing.T) {
 	source := []byte("1. download from the [Releases](https://github.com/example/releases) page\n2. unzip the file\n")
 
@@ -304,6 +448,24 @@ func TestParse_LinkInListItem(t *testing.T) {
 	}
 }
 
+func TestParse_HardBreakInListItem(t *testing.T) {
+	source := []byte("- line one  \n  line two\n- other\n")
+
+	blocks, err := Parse(source)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(blocks) != 1 {
+		t.Fatalf("expected 1 block, got %d", len(blocks))
+	}
+	if !strings.Contains(blocks[0].HTML, "<li>line one<br>\nline two</li>") {
+		t.Errorf("hard break in a list item should render as <br>, got %q", blocks[0].HTML)
+	}
+	if got := blocks[0].List.Texts()[0]; got != "line one  \nline two" {
+		t.Errorf("item text = %q, want the trailing spaces kept", got)
+	}
+}
+
 func TestParse_SampleFile(t *testing.T) {
 	source, err := os.ReadFile("../../testdata/sample.fr.md")
 	if err != nil {
@@ -329,3 +491,52 @@ func TestParse_SampleFile(t *testing.T) {
 		t.Errorf("first heading level should be 1, got %d", blocks[0].Level)
 	}
 }
+
+func TestParse_Lines(t *testing.T) {
+	input := "# Title\n\nFirst line\nsecond line.\n\n- item\n- *item*\n\n```go\ncode\n```\n\n> quote\n\n<div>\nhi\n</div>\n\n***\n\nSetext\n------\n"
+	blocks, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	// Thematic breaks have no text, hence no line.
+	want := []int{1, 3, 6, 9, 13, 15, 0, 21}
+	if len(blocks) != len(want) {
+		t.Fatalf("expected %d blocks, got %d", len(want), len(blocks))
+	}
+	for i, b := range blocks {
+		if b.Line != want[i] {
+			t.Errorf("block %d (%s): Line = %d, want %d", i, b.Kind, b.Line, want[i])
+		}
+	}
+}
+
+func TestParse_Table(t *testing.T) {
+	input := "| Name | Price |\n| :--- | ---: |\n| **Comté** | 12 |\n| Brie | `a\\|b` |\n"
+	blocks, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(blocks) != 1 || blocks[0].Kind != BlockTable {
+		t.Fatalf("expected one table block, got %+v", blocks)
+	}
+	b := blocks[0]
+	want := []string{"Name", "Price", "**Comté**", "12", "Brie", "`a\\|b`"}
+	if got := b.Table.Cells(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Cells = %q, want %q", got, want)
+	}
+	if b.Table.Align[0] != "left" || b.Table.Align[1] != "right" {
+		t.Errorf("Align = %q", b.Table.Align)
+	}
+	if !strings.Contains(b.HTML, "<table>") || !strings.Contains(b.HTML, `style="text-align:right"`) {
+		t.Errorf("HTML should be a table with the alignment, got %q", b.HTML)
+	}
+	if b.Text != "Name | Price\nComté | 12\nBrie | a|b" {
+		t.Errorf("Text = %q", b.Text)
+	}
+
+	// The markdown of the table parses back into the same cells.
+	again, err := Parse([]byte(b.Table.WithCells(b.Table.Cells()).Markdown()))
+	if err != nil || len(again) != 1 || again[0].Raw != b.Raw {
+		t.Errorf("round trip changed the table: %q", again[0].Raw)
+	}
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// Quote holds the structure of a blockquote, so that its paragraphs can
+// be translated one by one, like those of a List.
+type Quote struct {
+	Blocks []NestedBlock
+}
+
+// Texts returns the inline markdown of the paragraphs of the quote, nested
+// blocks included, in reading order.
+func (q *Quote) Texts() []string {
+	return blocksTexts(q.Blocks)
+}
+
+// WithTexts returns a copy of the quote with the texts of its paragraphs
+// replaced, in reading order, by texts. Missing texts are left empty.
+func (q *Quote) WithTexts(texts []string) *Quote {
+	k := 0
+	return q.withTexts(texts, &k)
+}
+
+func (q *Quote) withTexts(texts []string, k *int) *Quote {
+	return &Quote{Blocks: blocksWithTexts(q.Blocks, texts, k)}
+}
+
+// Inner returns the content of the quote in markdown, without its quote
+// markers: its blocks separated by blank lines.
+func (q *Quote) Inner() string {
+	parts := make([]string, len(q.Blocks))
+	for i, b := range q.Blocks {
+		parts[i] = strings.TrimRight(b.markdown(), "\n")
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Markdown returns the quote in markdown, each line of its content behind
+// a quote marker.
+func (q *Quote) Markdown() string {
+	return QuoteLines(q.Inner())
+}
+
+// QuoteLines puts a quote marker before each line of markdown.
+func QuoteLines(md string) string {
+	var buf strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(md, "\n"), "\n") {
+		if line == "" {
+			buf.WriteString(">\n")
+			continue
+		}
+		buf.WriteString("> " + line + "\n")
+	}
+	return buf.String()
+}
+
+// extractQuoteModel reads the structure of a blockquote node, and the plain
+// text of its paragraphs, one per line.
+func extractQuoteModel(bq *ast.Blockquote, source []byte) (*Quote, []string) {
+	blocks, text := extractNestedBlocks(bq, source)
+	return &Quote{Blocks: blocks}, text
+}
@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// Table holds the cells of a GFM table block, so that they can be
+// translated one by one.
+type Table struct {
+	Align []string   // alignment of each column: "left", "center", "right" or "" for none
+	Rows  [][]string // inline markdown of the cells, row by row; the first row is the header
+}
+
+// pipePattern matches a pipe that is not escaped, which would end a cell.
+var pipePattern = regexp.MustCompile(`(^|[^\\])\|`)
+
+// Cells returns the cells of the table in reading order.
+func (t *Table) Cells() []string {
+	var cells []string
+	for _, row := range t.Rows {
+		cells = append(cells, row...)
+	}
+	return cells
+}
+
+// WithCells returns a copy of the table with its cells replaced, in
+// reading order, by cells. Missing cells are left empty.
+func (t *Table) WithCells(cells []string) *Table {
+	result := &Table{Align: t.Align, Rows: make([][]string, len(t.Rows))}
+	k := 0
+	for i, row := range t.Rows {
+		result.Rows[i] = make([]string, len(row))
+		for j := range row {
+			if k < len(cells) {
+				result.Rows[i][j] = cells[k]
+			}
+			k++
+		}
+	}
+	return result
+}
+
+// Markdown returns the table as a GFM pipe table.
+func (t *Table) Markdown() string {
+	var buf strings.Builder
+	writeRow := func(cells []string) {
+		buf.WriteString("|")
+		for _, c := range cells {
+			c = strings.Join(strings.Fields(c), " ")
+			// Escape the pipes of translated cells, twice for runs of pipes.
+			c = pipePattern.ReplaceAllString(pipePattern.ReplaceAllString(c, `$1\|`), `$1\|`)
+			buf.WriteString(" " + c + " |")
+		}
+		buf.WriteString("\n")
+	}
+	for i, row := range t.Rows {
+		writeRow(row)
+		if i > 0 {
+			continue
+		}
+		delims := make([]string, len(row))
+		for j := range delims {
+			align := ""
+			if j < len(t.Align) {
+				align = t.Align[j]
+			}
+			switch align {
+			case "left":
+				delims[j] = ":---"
+			case "center":
+				delims[j] = ":---:"
+			case "right":
+				delims[j] = "---:"
+			default:
+				delims[j] = "---"
+			}
+		}
+		buf.WriteString("| " + strings.Join(delims, " | ") + " |\n")
+	}
+	return buf.String()
+}
+
+// Text returns the plain text of the table, one row per line with the
+// cells separated by " | ".
+func (t *Table) Text() string {
+	lines := make([]string, len(t.Rows))
+	for i, row := range t.Rows {
+		lines[i] = strings.Join(row, " | ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// extractTable reads the alignments and cells of a table node. The cells
+// keep their inline markdown, and the plain text of the block is built from
+// the text of the cells.
+func extractTable(table *east.Table, source []byte) (text string, t *Table) {
+	t = &Table{}
+	for _, a := range table.Alignments {
+		align := ""
+		if a != east.AlignNone {
+			align = a.String()
+		}
+		t.Align = append(t.Align, align)
+	}
+	var plain Table
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells, texts []string
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			cells = append(cells, strings.TrimSpace(extractLines(cell, source)))
+			texts = append(texts, collectText(cell, source))
+		}
+		t.Rows = append(t.Rows, cells)
+		plain.Rows = append(plain.Rows, texts)
+	}
+	return plain.Text(), t
+}
@@ -0,0 +1,70 @@
+// Package placeholder replaces fragments of text with opaque tokens that
+// survive machine translation, and puts them back afterwards.
+package placeholder
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// tokenPattern matches a token such as {{M3}}, tolerating the spaces that
+// translation engines sometimes insert inside it.
+var tokenPattern = regexp.MustCompile(`\{\{\s*([A-Z])\s*(\d+)\s*\}\}`)
+
+// Set holds the values behind the tokens of one text. Each Set uses a
+// one-letter prefix so that several Sets can be applied to the same text.
+type Set struct {
+	prefix byte
+	values []string
+}
+
+// NewSet returns an empty Set whose tokens use the given prefix (A-Z).
+func NewSet(prefix byte) *Set {
+	if prefix < 'A' || prefix > 'Z' {
+		panic("placeholder: prefix must be an uppercase ASCII letter")
+	}
+	return &Set{prefix: prefix}
+}
+
+// Add registers value and returns the token that stands for it.
+func (s *Set) Add(value string) string {
+	s.values = append(s.values, value)
+	return fmt.Sprintf("{{%c%d}}", s.prefix, len(s.values)-1)
+}
+
+// Len returns the number of tokens in the set.
+func (s *Set) Len() int {
+	return len(s.values)
+}
+
+// Value returns the value behind token number i.
+func (s *Set) Value(i int) string {
+	return s.values[i]
+}
+
+// Restore replaces this set's tokens in text with their values and returns
+// the values whose token did not come back.
+func (s *Set) Restore(text string) (string, []string) {
+	found := make([]bool, len(s.values))
+	restored := tokenPattern.ReplaceAllStringFunc(text, func(tok string) string {
+		m := tokenPattern.FindStringSubmatch(tok)
+		if m[1][0] != s.prefix {
+			return tok
+		}
+		i, err := strconv.Atoi(m[2])
+		if err != nil || i >= len(s.values) {
+			return tok
+		}
+		found[i] = true
+		return s.values[i]
+	})
+
+	var missing []string
+	for i, ok := range found {
+		if !ok {
+			missing = append(missing, s.values[i])
+		}
+	}
+	return restored, missing
+}
@@ -0,0 +1,43 @@
+package placeholder
+
+import "testing"
+
+func TestSet_RoundTrip(t *testing.T) {
+	s := NewSet('G')
+	text := "Use " + s.Add("bilingual_pdf") + " with " + s.Add("Chrome") + "."
+	if text != "Use {{G0}} with {{G1}}." {
+		t.Fatalf("unexpected masked text %q", text)
+	}
+
+	// Engines may add spaces inside tokens or reorder them.
+	restored, missing := s.Restore("Utilice {{ G1 }} con {{G0}}.")
+	if restored != "Utilice Chrome con bilingual_pdf." {
+		t.Errorf("unexpected restored text %q", restored)
+	}
+	if len(missing) != 0 {
+		t.Errorf("expected no missing values, got %q", missing)
+	}
+}
+
+func TestSet_Missing(t *testing.T) {
+	s := NewSet('M')
+	s.Add("`code`")
+	s.Add("https://example.com")
+
+	restored, missing := s.Restore("Texto {{M1}} sin código.")
+	if restored != "Texto https://example.com sin código." {
+		t.Errorf("unexpected restored text %q", restored)
+	}
+	if len(missing) != 1 || missing[0] != "`code`" {
+		t.Errorf("expected `code` to be reported missing, got %q", missing)
+	}
+}
+
+func TestSet_OtherPrefixUntouched(t *testing.T) {
+	g := NewSet('G')
+	g.Add("term")
+	restored, _ := g.Restore("{{M0}} and {{G0}} and {{G7}}")
+	if restored != "{{M0}} and term and {{G7}}" {
+		t.Errorf("tokens of other sets should be left alone, got %q", restored)
+	}
+}
@@ -0,0 +1,276 @@
+// Package po reads and writes gettext PO files, the format of Weblate,
+// Poedit and other translation platforms.
+package po
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Entry is a translatable message.
+type Entry struct {
+	Comments   []string // translator comments (# ...)
+	Extracted  []string // extracted comments (#. ...)
+	References []string // source references (#: file:line)
+	Flags      []string // flags (#, fuzzy), e.g. "fuzzy"
+	Context    string   // msgctxt
+	ID         string   // msgid: the source text
+	Str        string   // msgstr: the translation, empty if untranslated
+}
+
+// Fuzzy reports whether the translation needs review.
+func (e Entry) Fuzzy() bool {
+	return slices.Contains(e.Flags, "fuzzy")
+}
+
+// Field is a header field, such as "Language: es".
+type Field struct {
+	Name  string
+	Value string
+}
+
+// File is a PO file: header fields and entries.
+type File struct {
+	Header  []Field
+	Entries []Entry
+}
+
+// HeaderValue returns the value of the header field, or "".
+func (f *File) HeaderValue(name string) string {
+	for _, h := range f.Header {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+// Write encodes the file, the header first as the entry with an empty msgid.
+func (f *File) Write(w io.Writer) error {
+	var buf strings.Builder
+	if len(f.Header) > 0 {
+		var header strings.Builder
+		for _, h := range f.Header {
+			fmt.Fprintf(&header, "%s: %s\n", h.Name, h.Value)
+		}
+		buf.WriteString("msgid \"\"\n")
+		writeString(&buf, "msgstr", header.String())
+	}
+	for _, e := range f.Entries {
+		buf.WriteString("\n")
+		writeComments(&buf, "# ", e.Comments)
+		writeComments(&buf, "#. ", e.Extracted)
+		if len(e.References) > 0 {
+			fmt.Fprintf(&buf, "#: %s\n", strings.Join(e.References, " "))
+		}
+		if len(e.Flags) > 0 {
+			fmt.Fprintf(&buf, "#, %s\n", strings.Join(e.Flags, ", "))
+		}
+		if e.Context != "" {
+			writeString(&buf, "msgctxt", e.Context)
+		}
+		writeString(&buf, "msgid", e.ID)
+		writeString(&buf, "msgstr", e.Str)
+	}
+
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+func writeComments(buf *strings.Builder, prefix string, comments []string) {
+	for _, c := range comments {
+		for _, line := range strings.Split(c, "\n") {
+			buf.WriteString(strings.TrimRight(prefix+line, " ") + "\n")
+		}
+	}
+}
+
+// writeString writes a keyword and its quoted value. A value of several
+// lines is written one line per string, after an empty first string.
+func writeString(buf *strings.Builder, keyword, value string) {
+	lines := strings.SplitAfter(value, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= 1 {
+		fmt.Fprintf(buf, "%s %s\n", keyword, quote(value))
+		return
+	}
+	fmt.Fprintf(buf, "%s \"\"\n", keyword)
+	for _, line := range lines {
+		buf.WriteString(quote(line) + "\n")
+	}
+}
+
+func quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// Read decodes a PO file. Obsolete entries (#~) are skipped, and only the
+// first form of plural messages is kept.
+func Read(r io.Reader) (*File, error) {
+	f := &File{}
+	var e Entry
+	var field *string // string continued by the following quoted lines
+	started := false  // e has a msgid
+
+	flush := func() {
+		if !started {
+			e = Entry{}
+			return
+		}
+		if e.ID == "" && e.Context == "" {
+			f.Header = parseHeader(e.Str)
+		} else {
+			f.Entries = append(f.Entries, e)
+		}
+		e, field, started = Entry{}, nil, false
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if n == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#"):
+			if started {
+				flush()
+			}
+			addComment(&e, line)
+			continue
+		case strings.HasPrefix(line, `"`):
+			if field == nil {
+				return nil, fmt.Errorf("line %d: string outside of a message", n)
+			}
+			s, err := unquote(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			*field += s
+			continue
+		}
+
+		keyword, rest, _ := strings.Cut(line, " ")
+		value, err := unquote(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		switch {
+		case keyword == "msgctxt":
+			if started {
+				flush()
+			}
+			e.Context, field = value, &e.Context
+		case keyword == "msgid":
+			if started {
+				flush()
+			}
+			e.ID, field, started = value, &e.ID, true
+		case keyword == "msgid_plural":
+			var plural string
+			field = &plural
+		case keyword == "msgstr", keyword == "msgstr[0]":
+			e.Str, field = value, &e.Str
+		case strings.HasPrefix(keyword, "msgstr["):
+			var other string
+			field = &other
+		default:
+			return nil, fmt.Errorf("line %d: unknown keyword %q", n, keyword)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return f, nil
+}
+
+// addComment adds a comment line to the entry.
+func addComment(e *Entry, line string) {
+	kind, text := line[:min(2, len(line))], strings.TrimSpace(line[min(2, len(line)):])
+	switch kind {
+	case "#.":
+		e.Extracted = append(e.Extracted, text)
+	case "#:":
+		e.References = append(e.References, strings.Fields(text)...)
+	case "#,":
+		for _, flag := range strings.Split(text, ",") {
+			if flag = strings.TrimSpace(flag); flag != "" {
+				e.Flags = append(e.Flags, flag)
+			}
+		}
+	case "#|", "#~":
+		// previous and obsolete messages
+	default:
+		e.Comments = append(e.Comments, strings.TrimSpace(line[1:]))
+	}
+}
+
+// unquote decodes a C-style quoted PO string.
+func unquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("expected a quoted string, got %q", s)
+	}
+	s = s[1 : len(s)-1]
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			buf.WriteByte(c)
+			continue
+		}
+		if i++; i == len(s) {
+			return "", fmt.Errorf("unterminated escape in %q", s)
+		}
+		switch s[i] {
+		case 'n':
+			buf.WriteByte('\n')
+		case 't':
+			buf.WriteByte('\t')
+		case 'r':
+			buf.WriteByte('\r')
+		case 'a':
+			buf.WriteByte('\a')
+		case 'b':
+			buf.WriteByte('\b')
+		case 'f':
+			buf.WriteByte('\f')
+		case 'v':
+			buf.WriteByte('\v')
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			j := i
+			for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+				j++
+			}
+			v, _ := strconv.ParseUint(s[i:j], 8, 8)
+			buf.WriteByte(byte(v))
+			i = j - 1
+		default:
+			buf.WriteByte(s[i]) // \\, \" and \'
+		}
+	}
+	return buf.String(), nil
+}
+
+// parseHeader splits the header entry into fields.
+func parseHeader(s string) []Field {
+	var fields []Field
+	for _, line := range strings.Split(s, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields = append(fields, Field{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+	}
+	return fields
+}
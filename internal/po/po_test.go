@@ -0,0 +1,119 @@
+package po
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	f := &File{
+		Header: []Field{{Name: "Language", Value: "es"}, {Name: "Content-Type", Value: "text/plain; charset=UTF-8"}},
+		Entries: []Entry{
+			{Extracted: []string{"Heading 1"}, References: []string{"doc.md:1"}, Context: "0 Heading", ID: "# Bonjour", Str: "# Hola"},
+			{References: []string{"doc.md:3"}, Flags: []string{"fuzzy"}, Context: "1 List",
+				ID: "- un \"deux\"\n- trois\\\ttab\n", Str: "- uno \"dos\"\n- tres\\\ttab\n"},
+			{Context: "2 Paragraph", ID: "Sans traduction."},
+		},
+	}
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"msgid \"\"\nmsgstr \"\"\n\"Language: es\\n\"\n",
+		"#. Heading 1\n#: doc.md:1\nmsgctxt \"0 Heading\"\nmsgid \"# Bonjour\"\nmsgstr \"# Hola\"\n",
+		"#, fuzzy\nmsgctxt \"1 List\"\nmsgid \"\"\n\"- un \\\"deux\\\"\\n\"\n\"- trois\\\\\\ttab\\n\"\n",
+		"msgid \"Sans traduction.\"\nmsgstr \"\"\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q\n%s", want, out)
+		}
+	}
+
+	got, err := Read(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if got.HeaderValue("language") != "es" {
+		t.Errorf("Language header = %q", got.HeaderValue("language"))
+	}
+	if len(got.Entries) != len(f.Entries) {
+		t.Fatalf("got %d entries, want %d", len(got.Entries), len(f.Entries))
+	}
+	for i, want := range f.Entries {
+		e := got.Entries[i]
+		if e.Context != want.Context || e.ID != want.ID || e.Str != want.Str ||
+			!slices.Equal(e.Flags, want.Flags) || !slices.Equal(e.References, want.References) ||
+			!slices.Equal(e.Extracted, want.Extracted) {
+			t.Errorf("entry %d = %+v, want %+v", i, e, want)
+		}
+	}
+	if !got.Entries[1].Fuzzy() || got.Entries[0].Fuzzy() {
+		t.Error("only the second entry should be fuzzy")
+	}
+}
+
+func TestRead_EditorOutput(t *testing.T) {
+	// As written by translation tools: BOM, translator comments, previous
+	// messages, plurals and obsolete entries.
+	input := "\ufeff# Translators: Ana\n" +
+		"msgid \"\"\n" +
+		"msgstr \"\"\n" +
+		"\"Language: es\\n\"\n" +
+		"\"X-Generator: Poedit 3.4\\n\"\n" +
+		"\n" +
+		"# Checked by Ana\n" +
+		"#, fuzzy, markdown-text\n" +
+		"#| msgid \"Vieux texte\"\n" +
+		"msgctxt \"3 Paragraph\"\n" +
+		"msgid \"Texte\"\n" +
+		"msgstr \"Texto \\303\\251\"\n" +
+		"\n" +
+		"msgid \"chat\"\n" +
+		"msgid_plural \"chats\"\n" +
+		"msgstr[0] \"gato\"\n" +
+		"msgstr[1] \"gatos\"\n" +
+		"\n" +
+		"#~ msgid \"Obsolète\"\n" +
+		"#~ msgstr \"Obsoleto\"\n"
+
+	f, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if f.HeaderValue("X-Generator") != "Poedit 3.4" {
+		t.Errorf("unexpected header %+v", f.Header)
+	}
+	if len(f.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", f.Entries)
+	}
+	e := f.Entries[0]
+	if e.Context != "3 Paragraph" || e.ID != "Texte" || e.Str != "Texto é" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if !e.Fuzzy() || !slices.Equal(e.Flags, []string{"fuzzy", "markdown-text"}) {
+		t.Errorf("unexpected flags %q", e.Flags)
+	}
+	if !slices.Equal(e.Comments, []string{"Checked by Ana"}) {
+		t.Errorf("unexpected comments %q", e.Comments)
+	}
+	if p := f.Entries[1]; p.ID != "chat" || p.Str != "gato" {
+		t.Errorf("unexpected plural entry %+v", p)
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	for name, input := range map[string]string{
+		"unquoted":        "msgid hello\n",
+		"unknown keyword": "msgid \"a\"\nmsgtranslation \"b\"\n",
+		"stray string":    "\"a\"\n",
+		"bad escape":      "msgid \"a\\\"\n",
+	} {
+		if _, err := Read(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Package qa scores translations by back-translation: the translation is
+// translated back into the source language and compared with the original,
+// and blocks whose round trip drifted too far are flagged for review.
+package qa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+)
+
+// DefaultThreshold is the similarity below which a block is flagged.
+const DefaultThreshold = 0.5
+
+// Block is the quality check of one translated block.
+type Block struct {
+	Index           int     `json:"index"`
+	Kind            string  `json:"kind"`
+	Source          string  `json:"source"`
+	Translation     string  `json:"translation"`
+	BackTranslation string  `json:"back_translation"`
+	Score           float64 `json:"score"`
+	Flagged         bool    `json:"flagged"`
+}
+
+// Report is the result of a back-translation check.
+type Report struct {
+	Source    string    `json:"source"`
+	Target    string    `json:"target"`
+	Engine    string    `json:"engine"`
+	Threshold float64   `json:"threshold"`
+	Created   time.Time `json:"created"`
+	Mean      float64   `json:"mean_score"`
+	Flagged   int       `json:"flagged"`
+	Blocks    []Block   `json:"blocks"`
+}
+
+// NewReport returns an empty report for a language pair.
+func NewReport(source, target, engine string, threshold float64) *Report {
+	return &Report{
+		Source:    source,
+		Target:    target,
+		Engine:    engine,
+		Threshold: threshold,
+		Created:   time.Now().UTC(),
+		Blocks:    []Block{},
+	}
+}
+
+// Add scores a block and appends it to the report.
+func (r *Report) Add(b Block) {
+	b.Score = Similarity(b.Source, b.BackTranslation)
+	b.Flagged = b.Score < r.Threshold
+	r.Blocks = append(r.Blocks, b)
+
+	total := 0.0
+	r.Flagged = 0
+	for _, blk := range r.Blocks {
+		total += blk.Score
+		if blk.Flagged {
+			r.Flagged++
+		}
+	}
+	r.Mean = total / float64(len(r.Blocks))
+}
+
+// WriteTable prints the report as a table, flagged blocks marked with "!".
+func (r *Report) WriteTable(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "\tBlock\tKind\tScore\tSource")
+	_, _ = fmt.Fprintln(tw, "\t-----\t----\t-----\t------")
+	for _, b := range r.Blocks {
+		mark := ""
+		if b.Flagged {
+			mark = "!"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\t%.2f\t%s\n", mark, b.Index, b.Kind, b.Score, excerpt(b.Source, 50))
+	}
+	_ = tw.Flush()
+	_, _ = fmt.Fprintf(w, "%d of %d blocks below %.2f (mean score %.2f)\n", r.Flagged, len(r.Blocks), r.Threshold, r.Mean)
+}
+
+// Save writes the report as indented JSON.
+func (r *Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("saving QA report: %w", err)
+	}
+	return nil
+}
+
+// Similarity returns a score between 0 and 1 of how close two texts in the
+// same language are: the mean of the word overlap (F1 over the multisets of
+// words) and of the character trigram overlap (Dice coefficient), which
+// credits inflected forms and reordered phrases.
+func Similarity(a, b string) float64 {
+	wa, wb := words(a), words(b)
+	if len(wa) == 0 && len(wb) == 0 {
+		return 1
+	}
+	if len(wa) == 0 || len(wb) == 0 {
+		return 0
+	}
+	return (dice(count(wa), count(wb)) + dice(trigrams(wa), trigrams(wb))) / 2
+}
+
+// words splits text into lowercase words, ignoring punctuation.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func count(items []string) map[string]int {
+	m := make(map[string]int, len(items))
+	for _, it := range items {
+		m[it]++
+	}
+	return m
+}
+
+// trigrams counts the character trigrams of the words, padded with spaces.
+func trigrams(ws []string) map[string]int {
+	m := map[string]int{}
+	for _, w := range ws {
+		r := []rune(" " + w + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			m[string(r[i:i+3])]++
+		}
+	}
+	return m
+}
+
+// dice returns the Dice coefficient of two multisets, which for word
+// multisets equals the F1 score of their overlap.
+func dice(a, b map[string]int) float64 {
+	shared, na, nb := 0, 0, 0
+	for k, n := range a {
+		shared += min(n, b[k])
+		na += n
+	}
+	for _, n := range b {
+		nb += n
+	}
+	if na+nb == 0 {
+		return 0
+	}
+	return 2 * float64(shared) / float64(na+nb)
+}
+
+// excerpt returns the first line of text, shortened to at most n runes.
+func excerpt(text string, n int) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	r := []rune(line)
+	if len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return line
+}
@@ -0,0 +1,68 @@
+package qa
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"identical", "Le chat dort.", "le chat dort", 1, 1},
+		{"both empty", "", "  ", 1, 1},
+		{"one empty", "Le chat dort.", "", 0, 0},
+		{"close paraphrase", "Le chat dort sur le canapé.", "Le chat dort sur le sofa.", 0.6, 0.95},
+		{"inflection", "Les chats dormaient.", "Le chat dormait.", 0.3, 0.8},
+		{"unrelated", "Le chat dort sur le canapé.", "Il pleut beaucoup en automne.", 0, 0.2},
+	}
+	for _, tt := range tests {
+		got := Similarity(tt.a, tt.b)
+		if got < tt.min || got > tt.max {
+			t.Errorf("%s: Similarity(%q, %q) = %.2f, want in [%.2f, %.2f]", tt.name, tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	r := NewReport("fr", "es", "google", 0.5)
+	r.Add(Block{Index: 0, Kind: "Heading", Source: "Le chat", Translation: "El gato", BackTranslation: "Le chat"})
+	r.Add(Block{Index: 2, Kind: "Paragraph", Source: "Le chat dort sur le canapé.", Translation: "Llueve.", BackTranslation: "Il pleut beaucoup en automne."})
+
+	if r.Flagged != 1 || !r.Blocks[1].Flagged || r.Blocks[0].Flagged {
+		t.Errorf("expected only block 2 to be flagged, got %+v", r.Blocks)
+	}
+	if r.Mean <= 0.4 || r.Mean >= 0.7 {
+		t.Errorf("unexpected mean score %.2f", r.Mean)
+	}
+
+	var table bytes.Buffer
+	r.WriteTable(&table)
+	out := table.String()
+	if !strings.Contains(out, "!  2") || !strings.Contains(out, "1 of 2 blocks below 0.50") {
+		t.Errorf("unexpected table:\n%s", out)
+	}
+
+	path := filepath.Join(t.TempDir(), "doc.fr.es.qa.json")
+	if err := r.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Report
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	if loaded.Flagged != 1 || len(loaded.Blocks) != 2 || loaded.Blocks[1].BackTranslation == "" {
+		t.Errorf("unexpected saved report %+v", loaded)
+	}
+}
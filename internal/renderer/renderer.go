@@ -9,6 +9,10 @@ import (
 type BlockPair struct {
 	Source template.HTML
 	Target template.HTML
+
+	// Continues is set when the next row holds the next sentence of the
+	// same block, so that the rule between them is lighter.
+	Continues bool
 }
 
 // FontSizes holds the font sizes (in pt) for the HTML template.
@@ -29,6 +33,12 @@ var FontSizePresets = map[string]FontSizes{
 // DefaultFontSize is the default font size preset name.
 const DefaultFontSize = "medium"
 
+// PageSizes lists the page sizes of the PDF, as CSS @page sizes.
+var PageSizes = []string{"a3", "a4", "a5", "letter", "legal"}
+
+// DefaultPageSize is the default page size.
+const DefaultPageSize = "a4"
+
 // TemplateData holds all data for the HTML template.
 type TemplateData struct {
 	Title       string
@@ -36,6 +46,9 @@ type TemplateData struct {
 	TargetLabel string
 	Pairs       []BlockPair
 	Fonts       FontSizes
+	PageSize    string // one of PageSizes, DefaultPageSize if empty
+	Author      string
+	Date        string
 	Attribution bool
 }
 
@@ -45,6 +58,9 @@ func Render(data TemplateData) (string, error) {
 	if data.Fonts == (FontSizes{}) {
 		data.Fonts = FontSizePresets[DefaultFontSize]
 	}
+	if data.PageSize == "" {
+		data.PageSize = DefaultPageSize
+	}
 
 	tmpl, err := template.New("bilingual").Parse(htmlTemplate)
 	if err != nil {
@@ -380,6 +380,29 @@ func TestRender_Attribution(t *testing.T) {
 	})
 }
 
+func TestRender_ContinuedRows(t *testing.T) {
+	data := TemplateData{
+		Title:       "Sentences",
+		SourceLabel: "Français",
+		TargetLabel: "Español",
+		Pairs: []BlockPair{
+			{Source: "<p>Première phrase.</p>", Target: "<p>Primera frase.</p>", Continues: true},
+			{Source: "<p>Seconde phrase.</p>", Target: "<p>Segunda frase.</p>"},
+		},
+	}
+
+	html, err := Render(data)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if got := strings.Count(html, `<tr class="continues">`); got != 1 {
+		t.Errorf("expected 1 continued row, got %d", got)
+	}
+	if !strings.Contains(html, "tr.continues td") {
+		t.Error("should contain the style of continued rows")
+	}
+}
+
 func TestFontSizePresets_Values(t *testing.T) {
 	// Verify the preset map contains exactly the expected keys and values
 	expected := map[string]FontSizes{
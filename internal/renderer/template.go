@@ -5,9 +5,11 @@ const htmlTemplate = `<!DOCTYPE html>
 <head>
   <meta charset="utf-8">
   <title>{{.Title}}</title>
-  <style>
+  {{if .Author}}<meta name="author" content="{{.Author}}">
+  {{end}}{{if .Date}}<meta name="date" content="{{.Date}}">
+  {{end}}  <style>
     @page {
-      size: A4;
+      size: {{.PageSize}};
       margin: 0;
     }
     * {
@@ -46,6 +48,9 @@ const htmlTemplate = `<!DOCTYPE html>
       vertical-align: top;
       border-bottom: 1px solid #eee;
     }
+    tr.continues td {
+      border-bottom: 1px dotted #eee;
+    }
     td:first-child {
       border-right: 1px solid #ddd;
     }
@@ -62,6 +67,23 @@ const htmlTemplate = `<!DOCTYPE html>
       margin-bottom: 0.2em;
       padding-left: 1.5em;
     }
+    td table {
+      margin: 0.3em 0;
+      table-layout: auto;
+    }
+    td table th, td table td {
+      width: auto;
+      padding: 2px 6px;
+      border: 1px solid #ddd;
+      vertical-align: top;
+    }
+    td table th {
+      background: #f8f8f8;
+    }
+    td img {
+      max-width: 100%;
+      height: auto;
+    }
     code {
       background: #f8f8f8;
       padding: 1px 4px;
@@ -90,6 +112,21 @@ const htmlTemplate = `<!DOCTYPE html>
       border-top: 1px solid #ddd;
       margin: 0.5em 0;
     }
+    .item-continued,
+    .item-nested {
+      padding-left: 1.5em;
+    }
+    .fuzzy {
+      background: #fff8e1;
+      border-left: 3px solid #f0ad4e;
+      padding-left: 6px;
+    }
+    .fuzzy::before {
+      content: "fuzzy";
+      float: right;
+      font-size: {{.Fonts.Pre}}pt;
+      color: #b26a00;
+    }
     .attribution {
       text-align: center;
       font-style: italic;
@@ -112,7 +149,7 @@ const htmlTemplate = `<!DOCTYPE html>
     </thead>
     <tbody>
       {{range .Pairs}}
-      <tr>
+      <tr{{if .Continues}} class="continues"{{end}}>
         <td>{{.Source}}</td>
         <td>{{.Target}}</td>
       </tr>
@@ -0,0 +1,188 @@
+// Package sentence splits text into sentences with language-aware rules:
+// abbreviations and initials, French spacing before punctuation and inside
+// guillemets, and full stops of Chinese and Japanese, which are not
+// followed by a space.
+package sentence
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// abbreviations lists, per language, the words written with a final period
+// that does not end a sentence, in lower case and without the final period.
+var abbreviations = map[string][]string{
+	"": {"etc", "e.g", "i.e", "cf", "vs", "approx", "ca", "fig", "vol", "pp"},
+	"en": {"mr", "mrs", "ms", "dr", "prof", "sr", "jr", "st", "mt", "inc", "ltd", "co", "corp",
+		"jan", "feb", "mar", "apr", "jun", "jul", "aug", "sep", "sept", "oct", "nov", "dec",
+		"dept", "est", "gen", "gov", "capt", "col", "lt", "rev", "no"},
+	"fr": {"mm", "mme", "mmes", "mlle", "mlles", "dr", "pr", "me", "st", "ste", "av", "bd",
+		"ex", "env", "chap", "janv", "févr", "avr", "juil", "sept", "oct", "nov", "déc"},
+	"es": {"sr", "sra", "srta", "sres", "dr", "dra", "ud", "uds", "dña", "pág", "págs",
+		"ej", "aprox", "núm", "avda", "tel", "cía", "ee.uu"},
+	"de": {"hr", "fr", "dr", "prof", "nr", "str", "bzw", "usw", "vgl", "ggf", "evtl", "inkl",
+		"z.b", "d.h", "u.a", "abs", "jh", "mio", "mrd", "bspw"},
+	"it": {"sig", "sigg", "sig.ra", "dott", "dott.ssa", "prof", "ing", "avv", "ecc", "pag", "pagg", "cfr"},
+	"pt": {"sr", "sra", "srta", "dr", "dra", "prof", "av", "pág", "ex", "nº"},
+	"nl": {"dhr", "mevr", "dr", "prof", "bijv", "enz", "d.w.z", "o.a", "nr", "blz"},
+}
+
+// ordinalLanguages write ordinal numbers with a period, as in "am 3. Oktober".
+var ordinalLanguages = map[string]bool{"de": true, "da": true, "no": true, "fi": true, "cs": true, "pl": true, "hu": true}
+
+// Split returns the sentences of text in the given language (an ISO 639-1
+// code), without the white space between them. Text without sentence
+// punctuation is a single sentence.
+func Split(text, lang string) []string {
+	var sentences []string
+	start := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !isTerminator(r) {
+			i += size
+			continue
+		}
+		end := skipClosing(text, i)
+		if isBoundary(text, start, i, end, lang) {
+			if s := strings.TrimSpace(text[start:end]); s != "" {
+				sentences = append(sentences, s)
+			}
+			start = end
+		}
+		i = end
+	}
+	if s := strings.TrimSpace(text[start:]); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+func isTerminator(r rune) bool {
+	return strings.ContainsRune(".!?…‼⁇⁈⁉"+fullStops+"؟।", r)
+}
+
+// fullStops end a sentence without a following space.
+const fullStops = "。！？．"
+
+// skipClosing returns the end of the punctuation at i: further terminators,
+// then closing quotes, brackets and emphasis, possibly after a space as in
+// French "« Oui ! »".
+func skipClosing(text string, i int) int {
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !isTerminator(r) {
+			break
+		}
+		i += size
+	}
+	for {
+		j := i
+		for j < len(text) {
+			r, size := utf8.DecodeRuneInString(text[j:])
+			if !isInlineSpace(r) {
+				break
+			}
+			j += size
+		}
+		r, size := utf8.DecodeRuneInString(text[j:])
+		if j >= len(text) || !strings.ContainsRune(`"'”’»)]}》」』*_~`+"`", r) {
+			return i
+		}
+		// A space before a closing quote only belongs to French guillemets.
+		if j > i && r != '»' {
+			return i
+		}
+		i = j + size
+	}
+}
+
+// isBoundary reports whether the punctuation text[at:end] ends the sentence
+// that starts at start.
+func isBoundary(text string, start, at, end int, lang string) bool {
+	punct, _ := utf8.DecodeRuneInString(text[at:])
+	if strings.ContainsRune(fullStops, punct) {
+		return true
+	}
+	if end < len(text) {
+		r, _ := utf8.DecodeRuneInString(text[end:])
+		if !unicode.IsSpace(r) {
+			return false // e.g. 3.14, example.com or "?!"
+		}
+	}
+	// A sentence does not go on in lower case, as in "etc. and" or
+	// French "« Oui ! » dit-il".
+	if next := nextLetter(text[end:]); next != 0 && unicode.IsLower(next) {
+		return false
+	}
+	if punct != '.' {
+		return true
+	}
+
+	word := wordBefore(text[start:at])
+	switch {
+	case word == "":
+		return true
+	case utf8.RuneCountInString(word) == 1 && unicode.IsLetter([]rune(word)[0]):
+		return false // an initial, as in "J. K. Rowling"
+	case strings.Contains(word, "."):
+		return false // e.g. "U.S.A."
+	case isDigits(word):
+		return !ordinalLanguages[lang] || len(word) > 2
+	}
+	return !isAbbreviation(strings.ToLower(word), lang)
+}
+
+// nextLetter returns the first letter or digit of s after spaces, quotes
+// and opening punctuation, or 0.
+func nextLetter(s string) rune {
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			continue
+		}
+		return 0
+	}
+	return 0
+}
+
+// wordBefore returns the word that ends s: letters, digits and inner dots.
+func wordBefore(s string) string {
+	i := len(s)
+	for i > 0 {
+		r, size := utf8.DecodeLastRuneInString(s[:i])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != 'º' && r != '°' {
+			break
+		}
+		i -= size
+	}
+	return strings.Trim(s[i:], ".")
+}
+
+func isAbbreviation(word, lang string) bool {
+	for _, l := range []string{"", lang} {
+		for _, a := range abbreviations[l] {
+			if a == word {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isInlineSpace reports whether r is a space within a line, including the
+// no-break spaces of French typography.
+func isInlineSpace(r rune) bool {
+	return r == ' ' || r == '\u00a0' || r == '\u202f' || r == '\t'
+}
@@ -0,0 +1,48 @@
+package sentence
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		text string
+		want []string
+	}{
+		{"empty", "en", "  ", nil},
+		{"no punctuation", "en", "A title", []string{"A title"}},
+		{"simple", "en", "One. Two! Three? Four", []string{"One.", "Two!", "Three?", "Four"}},
+		{"lower case goes on", "en", "Apples, pears, etc. and more.", []string{"Apples, pears, etc. and more."}},
+		{"abbreviation", "en", "Ask Dr. Smith. He knows.", []string{"Ask Dr. Smith.", "He knows."}},
+		{"initials", "en", "J. K. Rowling wrote it. Then she rested.", []string{"J. K. Rowling wrote it.", "Then she rested."}},
+		{"acronym", "en", "Made in the U.S.A. For you.", []string{"Made in the U.S.A. For you."}},
+		{"decimal and domain", "en", "Pi is 3.14 on example.com today. Yes.", []string{"Pi is 3.14 on example.com today.", "Yes."}},
+		{"year", "en", "It was 1990. Then came more.", []string{"It was 1990.", "Then came more."}},
+		{"ellipsis", "en", "Wait... Now go.", []string{"Wait...", "Now go."}},
+		{"closing quote", "en", `He said "Stop." She did.`, []string{`He said "Stop."`, "She did."}},
+		{"emphasis", "en", "**Careful.** Read on.", []string{"**Careful.**", "Read on."}},
+		{"french spacing", "fr", "Bonjour ! Comment allez-vous ? Bien.", []string{"Bonjour !", "Comment allez-vous ?", "Bien."}},
+		{"french no-break spaces", "fr", "« Vraiment\u202f? »\u00a0Oui\u202f!", []string{"« Vraiment\u202f? »", "Oui\u202f!"}},
+		{"guillemets", "fr", "Il a crié « Au secours ! » Puis il est parti.", []string{"Il a crié « Au secours ! »", "Puis il est parti."}},
+		{"incise", "fr", "« Viens ! » dit-elle. Il vint.", []string{"« Viens ! » dit-elle.", "Il vint."}},
+		{"french abbreviation", "fr", "Voir M. Dupont et Mme. Durand. Merci.", []string{"Voir M. Dupont et Mme. Durand.", "Merci."}},
+		{"spanish", "es", "¿Qué hora es? ¡Es tarde! Vamos con la Sra. García.", []string{"¿Qué hora es?", "¡Es tarde!", "Vamos con la Sra. García."}},
+		{"german ordinal", "de", "Am 3. Oktober ist Feiertag. Wir feiern.", []string{"Am 3. Oktober ist Feiertag.", "Wir feiern."}},
+		{"german abbreviation", "de", "Obst, z.B. Äpfel. Gut.", []string{"Obst, z.B. Äpfel.", "Gut."}},
+		{"ordinal only in german", "en", "Chapter 3. Results follow.", []string{"Chapter 3.", "Results follow."}},
+		{"chinese", "zh", "今天天气很好。我们去公园吧！好吗？", []string{"今天天气很好。", "我们去公园吧！", "好吗？"}},
+		{"japanese", "ja", "これはペンです。「はい。」そうです。", []string{"これはペンです。", "「はい。」", "そうです。"}},
+		{"arabic", "ar", "كيف حالك؟ أنا بخير.", []string{"كيف حالك؟", "أنا بخير."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Split(tt.text, tt.lang)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split(%q, %q) = %q, want %q", tt.text, tt.lang, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+// Package tmx reads and writes translation memories in the TMX 1.4 format,
+// and looks up translations in them.
+package tmx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"bilingual_pdf/internal/xmltext"
+)
+
+// creationTool is the tool named in the header of exported files.
+const creationTool = "bilingual_pdf"
+
+// Pair is a source segment and its translation.
+type Pair struct {
+	Source string
+	Target string
+}
+
+// Document is a translation memory for one language pair.
+type Document struct {
+	SourceLang  string
+	TargetLang  string
+	ToolVersion string    // version of the creating tool, written in the header
+	Created     time.Time // written in the header if set
+	Pairs       []Pair
+}
+
+// Write encodes the document as TMX 1.4, one translation unit per pair.
+func (d *Document) Write(w io.Writer) error {
+	var buf strings.Builder
+	buf.WriteString(xml.Header)
+	buf.WriteString("<tmx version=\"1.4\">\n")
+	fmt.Fprintf(&buf, `  <header creationtool="%s" creationtoolversion="%s" segtype="paragraph"`+
+		` o-tmf="%s" adminlang="en" srclang="%s" datatype="plaintext"`,
+		creationTool, xmltext.Escape(d.ToolVersion), creationTool, xmltext.Escape(d.SourceLang))
+	if !d.Created.IsZero() {
+		fmt.Fprintf(&buf, ` creationdate="%s"`, d.Created.UTC().Format("20060102T150405Z"))
+	}
+	buf.WriteString("/>\n  <body>\n")
+	for _, p := range d.Pairs {
+		buf.WriteString("    <tu>\n")
+		fmt.Fprintf(&buf, "      <tuv xml:lang=\"%s\"><seg>%s</seg></tuv>\n", xmltext.Escape(d.SourceLang), xmltext.Escape(p.Source))
+		fmt.Fprintf(&buf, "      <tuv xml:lang=\"%s\"><seg>%s</seg></tuv>\n", xmltext.Escape(d.TargetLang), xmltext.Escape(p.Target))
+		buf.WriteString("    </tu>\n")
+	}
+	buf.WriteString("  </body>\n</tmx>\n")
+
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// Load reads the source → target pairs of a TMX file.
+func Load(path, source, target string) (*Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading translation memory: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	doc, err := Read(f, source, target)
+	if err != nil {
+		return nil, fmt.Errorf("parsing translation memory %s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// Read decodes the translation units of a TMX document that have a variant
+// in both the source and the target language. Languages are matched by their
+// primary subtag, so "fr-CA" is French. The content of inline elements
+// (native codes and highlighted text) is kept as text.
+func Read(r io.Reader, source, target string) (*Document, error) {
+	dec := xml.NewDecoder(r)
+	doc := &Document{SourceLang: source, TargetLang: target}
+	root := false
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "tmx":
+			root = true
+		case "tu":
+			var tu xmlUnit
+			if err := dec.DecodeElement(&tu, &start); err != nil {
+				return nil, err
+			}
+			if p, ok := tu.pair(source, target); ok {
+				doc.Pairs = append(doc.Pairs, p)
+			}
+		}
+	}
+	if !root {
+		return nil, fmt.Errorf("no <tmx> element")
+	}
+	return doc, nil
+}
+
+type xmlUnit struct {
+	Variants []struct {
+		Lang string  `xml:"lang,attr"` // xml:lang, or lang in TMX 1.1
+		Seg  segment `xml:"seg"`
+	} `xml:"tuv"`
+}
+
+// pair returns the first source and target variants of the unit.
+func (tu xmlUnit) pair(source, target string) (Pair, bool) {
+	var p Pair
+	for _, v := range tu.Variants {
+		switch {
+		case p.Source == "" && sameLanguage(v.Lang, source):
+			p.Source = v.Seg.text
+		case p.Target == "" && sameLanguage(v.Lang, target):
+			p.Target = v.Seg.text
+		}
+	}
+	return p, strings.TrimSpace(p.Source) != "" && strings.TrimSpace(p.Target) != ""
+}
+
+// sameLanguage reports whether the language tag is the language code.
+func sameLanguage(tag, code string) bool {
+	tag = strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+	code = strings.ToLower(code)
+	if strings.Contains(code, "-") {
+		return tag == code
+	}
+	primary, _, _ := strings.Cut(tag, "-")
+	return primary == code
+}
+
+// segment is the text of a <seg> element, including the content of its
+// inline elements.
+type segment struct {
+	text string
+}
+
+func (s *segment) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var buf strings.Builder
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			buf.Write(t)
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				s.text = buf.String()
+				return nil
+			}
+			depth--
+		}
+	}
+}
+
+// Memory looks up translations of segments. Segments match when they are
+// equal up to white space.
+type Memory struct {
+	entries map[string]string
+}
+
+// NewMemory returns an empty memory.
+func NewMemory() *Memory {
+	return &Memory{entries: map[string]string{}}
+}
+
+// Add stores pairs; a segment added again takes the later translation.
+func (m *Memory) Add(pairs []Pair) {
+	for _, p := range pairs {
+		m.entries[normalize(p.Source)] = p.Target
+	}
+}
+
+// Lookup returns the translation of text, if any.
+func (m *Memory) Lookup(text string) (string, bool) {
+	tr, ok := m.entries[normalize(text)]
+	return tr, ok
+}
+
+// Len returns the number of segments in the memory.
+func (m *Memory) Len() int {
+	return len(m.entries)
+}
+
+// normalize collapses runs of white space into a single space.
+func normalize(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,164 @@
+package tmx
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	doc := &Document{
+		SourceLang:  "fr",
+		TargetLang:  "es",
+		ToolVersion: "1.2.3",
+		Created:     time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+		Pairs: []Pair{
+			{Source: "Bonjour & bienvenue", Target: "Hola y bienvenido"},
+			{Source: "- Pain\n- <b>Vin</b>", Target: "- Pan\n- <b>Vino</b>"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<tmx version="1.4">`,
+		`creationtool="bilingual_pdf" creationtoolversion="1.2.3"`,
+		`srclang="fr"`,
+		`creationdate="20240301T103000Z"`,
+		`<tuv xml:lang="fr"><seg>Bonjour &amp; bienvenue</seg></tuv>`,
+		`<tuv xml:lang="es"><seg>- Pan` + "\n" + `- &lt;b&gt;Vino&lt;/b&gt;</seg></tuv>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %s\n%s", want, out)
+		}
+	}
+
+	got, err := Read(strings.NewReader(out), "fr", "es")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(got.Pairs) != len(doc.Pairs) {
+		t.Fatalf("got %d pairs, want %d", len(got.Pairs), len(doc.Pairs))
+	}
+	for i, p := range doc.Pairs {
+		if got.Pairs[i] != p {
+			t.Errorf("pair %d = %+v, want %+v", i, got.Pairs[i], p)
+		}
+	}
+}
+
+func TestRead_OtherTools(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4">
+  <header creationtool="SomeCAT" srclang="*all*" segtype="sentence" o-tmf="x" adminlang="en-US" datatype="rtf"/>
+  <body>
+    <tu tuid="1">
+      <prop type="x-domain">wine</prop>
+      <tuv xml:lang="FR-fr"><seg>Le vin <bpt i="1">**</bpt>rouge<ept i="1">**</ept></seg></tuv>
+      <tuv xml:lang="en-US"><seg>Red wine</seg></tuv>
+      <tuv xml:lang="es-ES"><seg>El vino <bpt i="1">**</bpt>tinto<ept i="1">**</ept></seg></tuv>
+    </tu>
+    <tu>
+      <tuv lang="fr_CA"><seg>Fromage</seg></tuv>
+      <tuv lang="es"><seg>Queso</seg></tuv>
+    </tu>
+    <tu>
+      <tuv xml:lang="fr"><seg>Sans traduction</seg></tuv>
+      <tuv xml:lang="en"><seg>Without translation</seg></tuv>
+    </tu>
+    <tu>
+      <tuv xml:lang="fr"><seg>Vide</seg></tuv>
+      <tuv xml:lang="es"><seg> </seg></tuv>
+    </tu>
+  </body>
+</tmx>`
+	doc, err := Read(strings.NewReader(input), "fr", "es")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	want := []Pair{
+		{Source: "Le vin **rouge**", Target: "El vino **tinto**"},
+		{Source: "Fromage", Target: "Queso"},
+	}
+	if len(doc.Pairs) != len(want) {
+		t.Fatalf("got pairs %+v, want %+v", doc.Pairs, want)
+	}
+	for i := range want {
+		if doc.Pairs[i] != want[i] {
+			t.Errorf("pair %d = %+v, want %+v", i, doc.Pairs[i], want[i])
+		}
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	for name, input := range map[string]string{
+		"not xml":  "hello",
+		"not tmx":  `<xliff version="2.0"></xliff>`,
+		"unclosed": `<tmx version="1.4"><body><tu>`,
+	} {
+		if _, err := Read(strings.NewReader(input), "fr", "es"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.tmx")
+	doc := &Document{SourceLang: "fr", TargetLang: "es", Pairs: []Pair{{Source: "Oui", Target: "Sí"}}}
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Load(path, "fr", "es")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(got.Pairs) != 1 || got.Pairs[0].Target != "Sí" {
+		t.Errorf("got pairs %+v", got.Pairs)
+	}
+	// The reverse direction reads the same units.
+	got, err = Load(path, "es", "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Pairs) != 1 || got.Pairs[0] != (Pair{Source: "Sí", Target: "Oui"}) {
+		t.Errorf("reverse pairs %+v", got.Pairs)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.tmx"), "fr", "es"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+	m.Add([]Pair{
+		{Source: "Bonjour  le\nmonde", Target: "Hola mundo"},
+		{Source: "Oui", Target: "Si"},
+	})
+	m.Add([]Pair{{Source: "Oui", Target: "Sí"}})
+
+	if m.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", m.Len())
+	}
+	for text, want := range map[string]string{
+		"Bonjour le monde": "Hola mundo",
+		" Oui\n":           "Sí",
+	} {
+		if got, ok := m.Lookup(text); !ok || got != want {
+			t.Errorf("Lookup(%q) = %q, %v; want %q", text, got, ok, want)
+		}
+	}
+	if _, ok := m.Lookup("Non"); ok {
+		t.Error("Lookup(\"Non\") found a translation")
+	}
+}
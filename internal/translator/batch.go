@@ -0,0 +1,220 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// batchMarkerPattern matches the numbered marker that precedes each block of
+// a batch, tolerating spaces inserted by the engine.
+var batchMarkerPattern = regexp.MustCompile(`\{\{\s*B\s*(\d+)\s*\}\}`)
+
+// BatchTranslator packs several blocks into each request to the wrapped
+// Translator, which cuts the number of round trips on long documents. Every
+// block of a batch is preceded by a numbered marker line; when the markers do
+// not come back intact, the blocks of that batch are translated one by one.
+// HTML blocks are sent alone, so that engines still see their leading tag
+// and translate them as HTML.
+type BatchTranslator struct {
+	Inner    Translator
+	MaxChars int       // maximum characters per request; larger blocks are sent alone
+	Progress io.Writer // if non-nil, batching statistics are printed here
+}
+
+// NewBatchTranslator wraps inner with batches of at most maxChars characters.
+func NewBatchTranslator(inner Translator, maxChars int, progress io.Writer) *BatchTranslator {
+	return &BatchTranslator{
+		Inner:    inner,
+		MaxChars: maxChars,
+		Progress: progress,
+	}
+}
+
+func (b *BatchTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	batches := b.pack(blocks)
+	if len(batches) == 0 {
+		return make([]string, len(blocks)), nil
+	}
+
+	texts := make([]string, len(batches))
+	for i, batch := range batches {
+		texts[i] = joinBatch(blocks, batch)
+	}
+	if b.Progress != nil {
+		_, _ = fmt.Fprintf(b.Progress, "Batching %d blocks into %d requests\n", countNonEmpty(blocks), len(batches))
+	}
+
+	// The blocks of a batch are complete when the batch is.
+	batchCtx := withCompletion(ctx, func(i int, text string) {
+		if i >= len(batches) {
+			return
+		}
+		if parts, ok := splitBatch(text, len(batches[i])); ok {
+			for j, idx := range batches[i] {
+				completed(ctx, idx, parts[j])
+			}
+		}
+	})
+	translated, err := b.Inner.Translate(batchCtx, texts, source, target)
+	results := make([]string, len(blocks))
+	var fallback []int
+	for i, batch := range batches {
+		if i >= len(translated) || translated[i] == "" {
+			continue
+		}
+		parts, ok := splitBatch(translated[i], len(batch))
+		if !ok {
+			fallback = append(fallback, batch...)
+			continue
+		}
+		for j, idx := range batch {
+			results[idx] = parts[j]
+		}
+	}
+	if err != nil {
+		// A failed batch stands for all of its blocks.
+		err = remapErrors(err, func(i int) []int { return batches[i] })
+	}
+
+	// Batches that came back malformed are retried even if others failed,
+	// so that their blocks are translated or reported.
+	if len(fallback) > 0 {
+		if b.Progress != nil {
+			_, _ = fmt.Fprintf(b.Progress, "Retranslating %d blocks of batches that came back malformed\n", len(fallback))
+		}
+		single := make([]string, len(fallback))
+		for j, idx := range fallback {
+			single[j] = blocks[idx]
+		}
+		singleCtx := withCompletion(ctx, func(j int, text string) {
+			if j < len(fallback) {
+				completed(ctx, fallback[j], text)
+			}
+		})
+		out, fallbackErr := b.Inner.Translate(singleCtx, single, source, target)
+		for j, idx := range fallback {
+			if j < len(out) {
+				results[idx] = out[j]
+			}
+		}
+		if fallbackErr != nil {
+			err = joinErrors(err, remapErrors(fallbackErr, indexIn(fallback)))
+		}
+	}
+	return results, err
+}
+
+// joinErrors returns the failures of two calls as one error: a single
+// BlockErrors if both list their blocks.
+func joinErrors(a, b error) error {
+	if a == nil {
+		return b
+	}
+	var errsA, errsB BlockErrors
+	if errors.As(a, &errsA) && errors.As(b, &errsB) {
+		return append(append(BlockErrors{}, errsA...), errsB...)
+	}
+	return errors.Join(a, b)
+}
+
+// pack groups the indices of the non-empty blocks, in order, into batches
+// of at most MaxChars characters (counting the markers). An HTML block is a
+// batch of its own.
+func (b *BatchTranslator) pack(blocks []string) [][]int {
+	var batches [][]int
+	var current []int
+	size := 0
+	for i, block := range blocks {
+		if block == "" {
+			continue
+		}
+		if isHTML(block) {
+			if len(current) > 0 {
+				batches = append(batches, current)
+				current, size = nil, 0
+			}
+			batches = append(batches, []int{i})
+			continue
+		}
+		n := len(block) + len(batchMarker(len(current))) + 3
+		if len(current) > 0 && size+n > b.MaxChars {
+			batches = append(batches, current)
+			current, size = nil, 0
+			n = len(block) + len(batchMarker(0)) + 3
+		}
+		current = append(current, i)
+		size += n
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// isHTML reports whether a block is raw HTML, which engines recognize by
+// its leading tag.
+func isHTML(block string) bool {
+	return strings.HasPrefix(strings.TrimSpace(block), "<")
+}
+
+// batchMarker returns the marker preceding block number i of a batch.
+func batchMarker(i int) string {
+	return "{{B" + strconv.Itoa(i) + "}}"
+}
+
+// joinBatch builds the request text for a batch. A batch of one block is
+// sent as is.
+func joinBatch(blocks []string, batch []int) string {
+	if len(batch) == 1 {
+		return blocks[batch[0]]
+	}
+	var buf strings.Builder
+	for j, idx := range batch {
+		if j > 0 {
+			buf.WriteString("\n\n")
+		}
+		buf.WriteString(batchMarker(j))
+		buf.WriteString("\n")
+		buf.WriteString(blocks[idx])
+	}
+	return buf.String()
+}
+
+// splitBatch splits a translated batch into n blocks. It reports false if
+// the markers are missing, duplicated or out of order.
+func splitBatch(text string, n int) ([]string, bool) {
+	if n == 1 {
+		return []string{text}, true
+	}
+	locs := batchMarkerPattern.FindAllStringSubmatchIndex(text, -1)
+	if len(locs) != n || strings.TrimSpace(text[:locs[0][0]]) != "" {
+		return nil, false
+	}
+	parts := make([]string, n)
+	for j, loc := range locs {
+		if num, err := strconv.Atoi(text[loc[2]:loc[3]]); err != nil || num != j {
+			return nil, false
+		}
+		end := len(text)
+		if j+1 < n {
+			end = locs[j+1][0]
+		}
+		parts[j] = strings.TrimSpace(text[loc[1]:end])
+	}
+	return parts, true
+}
+
+func countNonEmpty(blocks []string) int {
+	n := 0
+	for _, b := range blocks {
+		if b != "" {
+			n++
+		}
+	}
+	return n
+}
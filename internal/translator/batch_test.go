@@ -0,0 +1,222 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// recordingTranslator uppercases text, applies an optional mangling function
+// and records every request it receives.
+type recordingTranslator struct {
+	requests [][]string
+	mangle   func(string) string
+}
+
+func (r *recordingTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	r.requests = append(r.requests, append([]string(nil), blocks...))
+	results := make([]string, len(blocks))
+	for i, b := range blocks {
+		results[i] = strings.ToUpper(b)
+		if r.mangle != nil {
+			results[i] = r.mangle(results[i])
+		}
+	}
+	return results, nil
+}
+
+func TestBatchTranslator(t *testing.T) {
+	inner := &recordingTranslator{}
+	bt := NewBatchTranslator(inner, 1000, nil)
+
+	blocks := []string{"one", "", "two", "three"}
+	got, err := bt.Translate(context.Background(), blocks, "en", "fr")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+
+	want := []string{"ONE", "", "TWO", "THREE"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("block %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+	if len(inner.requests) != 1 || len(inner.requests[0]) != 1 {
+		t.Fatalf("expected a single request with one text, got %q", inner.requests)
+	}
+	if sent := inner.requests[0][0]; sent != "{{B0}}\none\n\n{{B1}}\ntwo\n\n{{B2}}\nthree" {
+		t.Errorf("unexpected batch text %q", sent)
+	}
+}
+
+func TestBatchTranslator_MaxChars(t *testing.T) {
+	inner := &recordingTranslator{}
+	bt := NewBatchTranslator(inner, 40, nil)
+
+	blocks := []string{"aaaaaaaaaa", "bbbbbbbbbb", "cccccccccc", strings.Repeat("d", 50)}
+	got, err := bt.Translate(context.Background(), blocks, "en", "fr")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+
+	sent := inner.requests[0]
+	if len(sent) != 3 {
+		t.Fatalf("expected 3 batches, got %d: %q", len(sent), sent)
+	}
+	for _, text := range sent[:2] {
+		if len(text) > 40 {
+			t.Errorf("batch of %d characters exceeds the limit: %q", len(text), text)
+		}
+	}
+	if sent[2] != blocks[3] {
+		t.Errorf("an oversized block should be sent alone and unmarked, got %q", sent[2])
+	}
+	for i, b := range blocks {
+		if got[i] != strings.ToUpper(b) {
+			t.Errorf("block %d: got %q", i, got[i])
+		}
+	}
+}
+
+func TestBatchTranslator_ToleratesMarkerSpacing(t *testing.T) {
+	inner := &recordingTranslator{mangle: func(s string) string {
+		return strings.ReplaceAll(s, "{{B1}}\n", "{{ B 1 }} ")
+	}}
+	bt := NewBatchTranslator(inner, 1000, nil)
+
+	got, err := bt.Translate(context.Background(), []string{"one", "two"}, "en", "fr")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if got[0] != "ONE" || got[1] != "TWO" {
+		t.Errorf("unexpected result %q", got)
+	}
+	if len(inner.requests) != 1 {
+		t.Errorf("expected no fallback requests, got %d requests", len(inner.requests))
+	}
+}
+
+func TestBatchTranslator_FallsBackOnMismatch(t *testing.T) {
+	inner := &recordingTranslator{mangle: func(s string) string {
+		return strings.ReplaceAll(s, "{{B1}}", "")
+	}}
+	bt := NewBatchTranslator(inner, 1000, nil)
+
+	got, err := bt.Translate(context.Background(), []string{"one", "two", "three"}, "en", "fr")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if len(inner.requests) != 2 {
+		t.Fatalf("expected a batched request and a fallback, got %d requests", len(inner.requests))
+	}
+	if len(inner.requests[1]) != 3 {
+		t.Errorf("fallback should send the blocks one by one, got %q", inner.requests[1])
+	}
+	if got[0] != "ONE" || got[1] != "TWO" || got[2] != "THREE" {
+		t.Errorf("unexpected result %q", got)
+	}
+}
+
+func TestSplitBatch(t *testing.T) {
+	tests := []struct {
+		text string
+		n    int
+		ok   bool
+	}{
+		{"{{B0}}\na\n\n{{B1}}\nb", 2, true},
+		{"{{B0}} a {{B1}} b", 2, true},
+		{"{{B1}}\na\n\n{{B0}}\nb", 2, false},
+		{"{{B0}}\na\n\n{{B0}}\nb", 2, false},
+		{"preamble {{B0}}\na\n\n{{B1}}\nb", 2, false},
+		{"{{B0}}\na b", 2, false},
+	}
+	for _, tt := range tests {
+		parts, ok := splitBatch(tt.text, tt.n)
+		if ok != tt.ok {
+			t.Errorf("splitBatch(%q) ok = %v, want %v", tt.text, ok, tt.ok)
+			continue
+		}
+		if ok && (parts[0] != "a" || parts[1] != "b") {
+			t.Errorf("splitBatch(%q) = %q", tt.text, parts)
+		}
+	}
+}
+
+func TestBatchTranslator_FailedBatch(t *testing.T) {
+	// The second batch is interrupted: its blocks are reported, the first
+	// batch is kept.
+	bt := NewBatchTranslator(interruptedTranslator{n: 1}, 40, nil)
+
+	blocks := []string{"aaaaaaaaaa", "bbbbbbbbbb", "", "cccccccccc", "dddddddddd"}
+	got, err := bt.Translate(context.Background(), blocks, "en", "fr")
+	var errs BlockErrors
+	if !errors.As(err, &errs) || !slices.Equal(errs.Indices(), []int{3, 4}) {
+		t.Fatalf("expected blocks 3 and 4 to fail, got %v", err)
+	}
+	if got[0] != "AAAAAAAAAA" || got[1] != "BBBBBBBBBB" || got[3] != "" || got[4] != "" {
+		t.Errorf("unexpected results %q", got)
+	}
+}
+
+// partialTranslator drops the markers of the first batch it is sent and
+// fails on the others; blocks sent one by one are uppercased, except fail.
+type partialTranslator struct {
+	fail     string
+	requests int
+}
+
+func (p *partialTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	p.requests++
+	results := make([]string, len(blocks))
+	var errs BlockErrors
+	for i, b := range blocks {
+		switch {
+		case p.requests == 1 && i == 0:
+			results[i] = strings.ToUpper(batchMarkerPattern.ReplaceAllString(b, ""))
+		case p.requests == 1 || b == p.fail:
+			errs = append(errs, &BlockError{Index: i, Err: errors.New("service unavailable")})
+		default:
+			results[i] = strings.ToUpper(b)
+		}
+	}
+	if len(errs) > 0 {
+		return results, errs
+	}
+	return results, nil
+}
+
+func TestBatchTranslator_FallbackAfterFailedBatch(t *testing.T) {
+	// Batch [0 1] comes back malformed and batch [2 3] fails: the blocks of
+	// the first are still retried one by one, and the failures of both
+	// are reported.
+	inner := &partialTranslator{fail: "bbbbbbbbbb"}
+	bt := NewBatchTranslator(inner, 40, nil)
+
+	blocks := []string{"aaaaaaaaaa", "bbbbbbbbbb", "cccccccccc", "dddddddddd"}
+	got, err := bt.Translate(context.Background(), blocks, "en", "fr")
+	var errs BlockErrors
+	if !errors.As(err, &errs) || !slices.Equal(errs.Indices(), []int{2, 3, 1}) {
+		t.Fatalf("expected blocks 2, 3 and 1 to fail, got %v", err)
+	}
+	if inner.requests != 2 {
+		t.Errorf("expected a fallback request, got %d requests", inner.requests)
+	}
+	if got[0] != "AAAAAAAAAA" || got[1] != "" {
+		t.Errorf("unexpected results %q", got)
+	}
+}
+
+func TestBatchTranslator_HTMLAlone(t *testing.T) {
+	inner := &recordingTranslator{}
+	bt := NewBatchTranslator(inner, 1000, nil)
+
+	if _, err := bt.Translate(context.Background(), []string{"one", "two", "<p>three</p>", "four"}, "en", "fr"); err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	want := []string{"{{B0}}\none\n\n{{B1}}\ntwo", "<p>three</p>", "four"}
+	if len(inner.requests) != 1 || !slices.Equal(inner.requests[0], want) {
+		t.Errorf("an HTML block should be a request of its own, got %q", inner.requests)
+	}
+}
@@ -0,0 +1,83 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"bilingual_pdf/internal/cache"
+)
+
+// CachedTranslator consults a translation memory before calling the wrapped
+// Translator, and stores what it translates.
+type CachedTranslator struct {
+	Inner    Translator
+	Table    *cache.Table
+	Refresh  bool      // ignore cached entries, but still store new translations
+	Progress io.Writer // if non-nil, hit/miss counts are printed here
+}
+
+// NewCachedTranslator wraps inner with the given cache table.
+func NewCachedTranslator(inner Translator, table *cache.Table, progress io.Writer) *CachedTranslator {
+	return &CachedTranslator{
+		Inner:    inner,
+		Table:    table,
+		Progress: progress,
+	}
+}
+
+func (c *CachedTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	results := make([]string, len(blocks))
+
+	// Collect distinct texts that are not cached yet.
+	var misses []string
+	missIndex := map[string][]int{}
+	hits := 0
+	for i, block := range blocks {
+		if block == "" {
+			continue
+		}
+		if !c.Refresh {
+			if tr, ok := c.Table.Get(block); ok {
+				results[i] = tr
+				hits++
+				continue
+			}
+		}
+		if _, seen := missIndex[block]; !seen {
+			misses = append(misses, block)
+		}
+		missIndex[block] = append(missIndex[block], i)
+	}
+
+	if c.Progress != nil {
+		_, _ = fmt.Fprintf(c.Progress, "Cache: %d hits, %d to translate\n", hits, len(misses))
+	}
+
+	var err error
+	if len(misses) > 0 {
+		var translated []string
+		translated, err = c.Inner.Translate(ctx, misses, source, target)
+		// Keep what was translated before a failure.
+		for j, text := range misses {
+			if j >= len(translated) || translated[j] == "" {
+				continue
+			}
+			c.Table.Put(text, translated[j])
+			for _, i := range missIndex[text] {
+				results[i] = translated[j]
+			}
+		}
+		err = remapErrors(err, func(j int) []int {
+			if j < 0 || j >= len(misses) {
+				return nil
+			}
+			return missIndex[misses[j]]
+		})
+	}
+
+	if saveErr := c.Table.Save(); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return results, err
+}
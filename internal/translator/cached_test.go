@@ -0,0 +1,88 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"bilingual_pdf/internal/cache"
+)
+
+// countingTranslator records the blocks it is asked to translate.
+type countingTranslator struct {
+	calls [][]string
+}
+
+func (c *countingTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	c.calls = append(c.calls, append([]string(nil), blocks...))
+	return upperTranslator{}.Translate(ctx, blocks, source, target)
+}
+
+func TestCachedTranslator(t *testing.T) {
+	mem := cache.New(t.TempDir())
+	table, err := mem.Open("test", "fr", "es")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	inner := &countingTranslator{}
+	ct := NewCachedTranslator(inner, table, nil)
+
+	got, err := ct.Translate(context.Background(), []string{"un", "", "deux", "un"}, "fr", "es")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if got[0] != "UN" || got[1] != "" || got[2] != "DEUX" || got[3] != "UN" {
+		t.Errorf("unexpected results %q", got)
+	}
+	if len(inner.calls) != 1 || len(inner.calls[0]) != 2 {
+		t.Fatalf("expected one call with 2 distinct texts, got %q", inner.calls)
+	}
+
+	// Second run: only the new text reaches the engine.
+	table, _ = mem.Open("test", "fr", "es")
+	ct = NewCachedTranslator(inner, table, nil)
+	got, err = ct.Translate(context.Background(), []string{"un", "trois"}, "fr", "es")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if got[0] != "UN" || got[1] != "TROIS" {
+		t.Errorf("unexpected results %q", got)
+	}
+	if len(inner.calls) != 2 || len(inner.calls[1]) != 1 || inner.calls[1][0] != "trois" {
+		t.Errorf("expected only the uncached text to be translated, got %q", inner.calls)
+	}
+
+	// Refresh: everything is retranslated.
+	ct.Refresh = true
+	if _, err := ct.Translate(context.Background(), []string{"un"}, "fr", "es"); err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if len(inner.calls) != 3 || inner.calls[2][0] != "un" {
+		t.Errorf("refresh should bypass the cache, got %q", inner.calls)
+	}
+}
+
+func TestCachedTranslator_KeepsPartialResults(t *testing.T) {
+	table, err := cache.New(t.TempDir()).Open("test", "fr", "es")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	ct := NewCachedTranslator(interruptedTranslator{n: 1}, table, nil)
+
+	got, err := ct.Translate(context.Background(), []string{"un", "deux", "un"}, "fr", "es")
+	var errs BlockErrors
+	if !errors.As(err, &errs) || !slices.Equal(errs.Indices(), []int{1}) {
+		t.Fatalf("expected block 1 to fail, got %v", err)
+	}
+	if got[0] != "UN" || got[1] != "" || got[2] != "UN" {
+		t.Errorf("unexpected results %q", got)
+	}
+	if tr, ok := table.Get("un"); !ok || tr != "UN" {
+		t.Errorf("completed block should be cached, got %q, %v", tr, ok)
+	}
+	if _, ok := table.Get("deux"); ok {
+		t.Error("failed block should not be cached")
+	}
+}
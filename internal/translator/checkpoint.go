@@ -0,0 +1,179 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+
+	"bilingual_pdf/internal/cache"
+)
+
+// DefaultCheckpointChunk is the number of blocks completed between two
+// checkpoint writes.
+const DefaultCheckpointChunk = 16
+
+// checkpointFile is the on-disk format of a checkpoint.
+type checkpointFile struct {
+	Engine       string            `json:"engine"`
+	Source       string            `json:"source"`
+	Target       string            `json:"target"`
+	Translations map[string]string `json:"translations"` // keyed by cache.Key of the source text
+}
+
+// CheckpointTranslator makes a translation run resumable: it records
+// completed blocks in a checkpoint file as the wrapped Translator reports
+// them, every Chunk blocks and when the run fails or is interrupted, and on
+// the next run skips blocks found in the checkpoint. All the blocks are
+// passed on in one call, so that the workers of the engine are kept busy.
+// The file is removed once every block has been translated.
+type CheckpointTranslator struct {
+	Inner    Translator
+	Path     string
+	Engine   string    // recorded so that a checkpoint is only reused by the same engine
+	Chunk    int       // blocks completed between two writes (values below 1 mean DefaultCheckpointChunk)
+	Progress io.Writer // if non-nil, resume information is printed here
+}
+
+// NewCheckpointTranslator wraps inner with a checkpoint stored at path.
+func NewCheckpointTranslator(inner Translator, path, engine string, progress io.Writer) *CheckpointTranslator {
+	return &CheckpointTranslator{
+		Inner:    inner,
+		Path:     path,
+		Engine:   engine,
+		Chunk:    DefaultCheckpointChunk,
+		Progress: progress,
+	}
+}
+
+func (c *CheckpointTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	cp, err := c.load(source, target)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]string, len(blocks))
+	var pending []int
+	resumed := 0
+	for i, block := range blocks {
+		if block == "" {
+			continue
+		}
+		if tr, ok := cp.Translations[cache.Key(block)]; ok {
+			results[i] = tr
+			resumed++
+			continue
+		}
+		pending = append(pending, i)
+	}
+	if resumed > 0 && c.Progress != nil {
+		_, _ = fmt.Fprintf(c.Progress, "Resuming from checkpoint %s: %d of %d blocks already translated\n", c.Path, resumed, resumed+len(pending))
+	}
+
+	chunk := c.Chunk
+	if chunk < 1 {
+		chunk = DefaultCheckpointChunk
+	}
+	texts := make([]string, len(pending))
+	for j, i := range pending {
+		texts[j] = blocks[i]
+	}
+
+	var (
+		mu      sync.Mutex
+		unsaved int
+		saveErr error
+	)
+	record := func(j int, text string) {
+		if j >= len(pending) || text == "" {
+			return
+		}
+		key := cache.Key(blocks[pending[j]])
+		if cp.Translations[key] == text {
+			return
+		}
+		cp.Translations[key] = text
+		unsaved++
+	}
+	// Blocks are recorded as the engine completes them; a failed write
+	// ends the run once the engine returns.
+	innerCtx := withCompletion(ctx, func(j int, text string) {
+		mu.Lock()
+		defer mu.Unlock()
+		record(j, text)
+		if unsaved >= chunk && saveErr == nil {
+			saveErr = c.save(cp)
+			unsaved = 0
+		}
+	})
+	translated, err := c.Inner.Translate(innerCtx, texts, source, target)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for j, i := range pending {
+		if j < len(translated) && translated[j] != "" {
+			results[i] = translated[j]
+			record(j, translated[j])
+		}
+	}
+	if saveErr != nil {
+		return results, saveErr
+	}
+	if err != nil {
+		if unsaved > 0 {
+			if err := c.save(cp); err != nil {
+				return results, err
+			}
+		}
+		if len(cp.Translations) > 0 && c.Progress != nil {
+			_, _ = fmt.Fprintf(c.Progress, "Checkpoint saved to %s; re-run the same command to resume\n", c.Path)
+		}
+		return results, remapErrors(err, indexIn(pending))
+	}
+
+	if err := os.Remove(c.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("removing checkpoint: %w", err)
+	}
+	return results, nil
+}
+
+// load reads the checkpoint, ignoring one written by another engine or
+// language pair.
+func (c *CheckpointTranslator) load(source, target string) (*checkpointFile, error) {
+	fresh := &checkpointFile{Engine: c.Engine, Source: source, Target: target, Translations: map[string]string{}}
+
+	data, err := os.ReadFile(c.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fresh, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint: %w", err)
+	}
+	var cp checkpointFile
+	if err := json.Unmarshal(data, &cp); err != nil || cp.Translations == nil {
+		return fresh, nil
+	}
+	if cp.Engine != c.Engine || cp.Source != source || cp.Target != target {
+		return fresh, nil
+	}
+	return &cp, nil
+}
+
+func (c *CheckpointTranslator) save(cp *checkpointFile) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding checkpoint: %w", err)
+	}
+	tmp := c.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("saving checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, c.Path); err != nil {
+		return fmt.Errorf("saving checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// flakyTranslator uppercases blocks, reporting each one as it completes
+// like an engine does, but fails on the given text.
+type flakyTranslator struct {
+	failOn string
+	seen   []string
+	calls  int
+}
+
+func (f *flakyTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	f.calls++
+	results := make([]string, len(blocks))
+	for i, b := range blocks {
+		if b == f.failOn {
+			return nil, errors.New("service unavailable")
+		}
+		f.seen = append(f.seen, b)
+		results[i] = strings.ToUpper(b)
+		completed(ctx, i, results[i])
+	}
+	return results, nil
+}
+
+func TestCheckpointTranslator_Resume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.fr.es.checkpoint.json")
+	blocks := []string{"a", "b", "", "c", "d", "e"}
+
+	// First run fails on d; the blocks completed before are checkpointed.
+	first := &flakyTranslator{failOn: "d"}
+	ct := NewCheckpointTranslator(first, path, "test", nil)
+	ct.Chunk = 2
+	if _, err := ct.Translate(context.Background(), blocks, "fr", "es"); err == nil {
+		t.Fatal("expected the first run to fail")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("checkpoint should exist after a failed run: %v", err)
+	}
+
+	// Second run only translates what is missing.
+	second := &flakyTranslator{}
+	ct = NewCheckpointTranslator(second, path, "test", nil)
+	ct.Chunk = 2
+	got, err := ct.Translate(context.Background(), blocks, "fr", "es")
+	if err != nil {
+		t.Fatalf("resumed run failed: %v", err)
+	}
+
+	want := []string{"A", "B", "", "C", "D", "E"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("block %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+	if strings.Join(second.seen, ",") != "d,e" {
+		t.Errorf("resumed run should only translate d and e, translated %q", second.seen)
+	}
+	if second.calls != 1 {
+		t.Errorf("the blocks should be passed on in one call, got %d calls", second.calls)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("checkpoint should be removed after a complete run")
+	}
+}
+
+// interruptedTranslator uppercases the first n blocks of a call and reports
+// the others as cancelled, like an engine stopped by Ctrl-C.
+type interruptedTranslator struct {
+	n int
+}
+
+func (it interruptedTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	results := make([]string, len(blocks))
+	var errs BlockErrors
+	for i, b := range blocks {
+		if i < it.n {
+			results[i] = strings.ToUpper(b)
+		} else {
+			errs = append(errs, &BlockError{Index: i, Err: context.Canceled})
+		}
+	}
+	return results, errs
+}
+
+func TestCheckpointTranslator_SavesInterruptedChunk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cp.json")
+	blocks := []string{"a", "b", "c", "d"}
+
+	ct := NewCheckpointTranslator(interruptedTranslator{n: 3}, path, "test", nil)
+	got, err := ct.Translate(context.Background(), blocks, "fr", "es")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancellation error, got %v", err)
+	}
+	var errs BlockErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Index != 3 {
+		t.Errorf("expected block 3 to fail, got %v", err)
+	}
+	if strings.Join(got, ",") != "A,B,C," {
+		t.Errorf("expected the completed blocks, got %q", got)
+	}
+
+	// The blocks completed before the interruption are not translated again.
+	second := &flakyTranslator{}
+	ct = NewCheckpointTranslator(second, path, "test", nil)
+	if _, err := ct.Translate(context.Background(), blocks, "fr", "es"); err != nil {
+		t.Fatalf("resumed run failed: %v", err)
+	}
+	if strings.Join(second.seen, ",") != "d" {
+		t.Errorf("resumed run should only translate d, translated %q", second.seen)
+	}
+}
+
+func TestCheckpointTranslator_IgnoresOtherEngine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cp.json")
+
+	ct := NewCheckpointTranslator(&flakyTranslator{failOn: "b"}, path, "deepl", nil)
+	ct.Chunk = 1
+	_, _ = ct.Translate(context.Background(), []string{"a", "b"}, "fr", "es")
+
+	other := &flakyTranslator{}
+	ct = NewCheckpointTranslator(other, path, "google", nil)
+	if _, err := ct.Translate(context.Background(), []string{"a", "b"}, "fr", "es"); err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if len(other.seen) != 2 {
+		t.Errorf("checkpoint of another engine should be ignored, translated %q", other.seen)
+	}
+}
+
+func TestCheckpointTranslator_SavesAsBlocksComplete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cp.json")
+	blocks := []string{"a", "b", "c", "d", "e", "f"}
+
+	// Batches of two blocks; the engine fails on the third batch, after
+	// the blocks of the first two were reported complete.
+	engine := &flakyTranslator{failOn: batchText(blocks[4:])}
+	ct := NewCheckpointTranslator(NewBatchTranslator(engine, 20, nil), path, "test", nil)
+	ct.Chunk = 2
+	if _, err := ct.Translate(context.Background(), blocks, "fr", "es"); err == nil {
+		t.Fatal("expected the run to fail")
+	}
+	if engine.calls != 1 {
+		t.Errorf("the batches should be sent in one call, got %d calls", engine.calls)
+	}
+
+	second := &flakyTranslator{}
+	ct = NewCheckpointTranslator(second, path, "test", nil)
+	if _, err := ct.Translate(context.Background(), blocks, "fr", "es"); err != nil {
+		t.Fatalf("resumed run failed: %v", err)
+	}
+	if strings.Join(second.seen, ",") != "e,f" {
+		t.Errorf("resumed run should only translate e and f, translated %q", second.seen)
+	}
+}
+
+// batchText returns the request text of a batch of blocks.
+func batchText(blocks []string) string {
+	batch := make([]int, len(blocks))
+	for i := range batch {
+		batch[i] = i
+	}
+	return joinBatch(blocks, batch)
+}
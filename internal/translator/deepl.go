@@ -0,0 +1,253 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DeepL API base URLs. Keys of the free plan end in ":fx".
+const (
+	DeepLFreeEndpoint = "https://api-free.deepl.com"
+	DeepLProEndpoint  = "https://api.deepl.com"
+)
+
+// deeplMaxTexts is the maximum number of texts DeepL accepts per request.
+const deeplMaxTexts = 50
+
+// DeepLTranslator implements Translator using the DeepL REST API.
+type DeepLTranslator struct {
+	AuthKey     string
+	Endpoint    string       // API base URL, e.g. DeepLFreeEndpoint
+	Formality   string       // "", "more", "less", "prefer_more" or "prefer_less"
+	TagHandling string       // "auto" (HTML blocks only), "html", "xml" or "" (none)
+	Client      *http.Client // if nil, http.DefaultClient is used
+	Progress    io.Writer    // if non-nil, progress is printed here
+	Concurrency              // applies to requests, each of up to deeplMaxTexts blocks
+}
+
+// NewDeepLTranslator creates a DeepLTranslator, choosing the free or pro
+// endpoint from the auth key.
+func NewDeepLTranslator(authKey string, progress io.Writer) *DeepLTranslator {
+	endpoint := DeepLProEndpoint
+	if strings.HasSuffix(authKey, ":fx") {
+		endpoint = DeepLFreeEndpoint
+	}
+	return &DeepLTranslator{
+		AuthKey:     authKey,
+		Endpoint:    endpoint,
+		TagHandling: "auto",
+		Progress:    progress,
+		Concurrency: Concurrency{Workers: 1, Retry: DefaultRetryPolicy, Timeout: DefaultTimeout},
+	}
+}
+
+func init() {
+	Register(Engine{
+		Name:        "deepl",
+		Description: "DeepL API (key from DEEPL_AUTH_KEY)",
+		Options:     []string{"auth_key", "plan", "endpoint", "formality", "tag_handling"},
+		Secrets:     []string{"auth_key"},
+		New: func(cfg Config) (Translator, error) {
+			key := cfg.Option("auth_key", os.Getenv("DEEPL_AUTH_KEY"))
+			if key == "" {
+				return nil, fmt.Errorf("missing auth key: set DEEPL_AUTH_KEY or --engine-opt auth_key=...")
+			}
+			d := NewDeepLTranslator(key, cfg.Progress)
+			switch plan := cfg.Option("plan", ""); plan {
+			case "":
+			case "free":
+				d.Endpoint = DeepLFreeEndpoint
+			case "pro":
+				d.Endpoint = DeepLProEndpoint
+			default:
+				return nil, fmt.Errorf("invalid plan %q: must be free or pro", plan)
+			}
+			d.Endpoint = cfg.Option("endpoint", d.Endpoint)
+			d.Formality = cfg.Option("formality", "")
+			d.TagHandling = cfg.Option("tag_handling", d.TagHandling)
+			d.Concurrency = cfg.concurrency(d.Concurrency)
+			return d, nil
+		},
+	})
+}
+
+type deeplRequest struct {
+	Text        []string `json:"text"`
+	SourceLang  string   `json:"source_lang,omitempty"`
+	TargetLang  string   `json:"target_lang"`
+	Formality   string   `json:"formality,omitempty"`
+	TagHandling string   `json:"tag_handling,omitempty"`
+}
+
+type deeplResponse struct {
+	Translations []struct {
+		DetectedSourceLanguage string `json:"detected_source_language"`
+		Text                   string `json:"text"`
+	} `json:"translations"`
+}
+
+func (d *DeepLTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	results := make([]string, len(blocks))
+
+	// Group non-empty blocks by tag handling so HTML blocks can be sent with
+	// tag_handling=html while markdown is sent as plain text, then split
+	// the groups into requests.
+	groups := map[string][]int{}
+	var order []string
+	total := 0
+	for i, block := range blocks {
+		if block == "" {
+			continue
+		}
+		th := d.tagHandlingFor(block)
+		if _, ok := groups[th]; !ok {
+			order = append(order, th)
+		}
+		groups[th] = append(groups[th], i)
+		total++
+	}
+	var requests []deeplBatch
+	for _, th := range order {
+		indices := groups[th]
+		for start := 0; start < len(indices); start += deeplMaxTexts {
+			requests = append(requests, deeplBatch{indices[start:min(start+deeplMaxTexts, len(indices))], th})
+		}
+	}
+
+	// The pool sees one job per request; blocks are reported done by the
+	// job, on ctx, rather than by the pool with the index of the request.
+	jobs := make([]string, len(requests))
+	for i := range jobs {
+		jobs[i] = requests[i].tagHandling + "#"
+	}
+	var (
+		mu   sync.Mutex
+		done int
+	)
+	_, err := translateEach(withCompletion(ctx, func(int, string) {}), jobs, d.Concurrency, nil, func(reqCtx context.Context, _, i int, _ string) (string, error) {
+		batch := requests[i]
+		texts := make([]string, len(batch.indices))
+		for j, idx := range batch.indices {
+			texts[j] = blocks[idx]
+		}
+		translated, err := d.translateBatch(reqCtx, texts, source, target, batch.tagHandling)
+		if err != nil {
+			return "", err
+		}
+		mu.Lock()
+		for j, idx := range batch.indices {
+			results[idx] = translated[j]
+		}
+		done += len(batch.indices)
+		if d.Progress != nil {
+			_, _ = fmt.Fprintf(d.Progress, "Translating block %d/%d...\r", done, total)
+		}
+		mu.Unlock()
+		for j, idx := range batch.indices {
+			completed(ctx, idx, translated[j])
+		}
+		return "", nil
+	})
+	if err != nil {
+		var failed BlockErrors
+		if !errors.As(err, &failed) {
+			return results, err
+		}
+		var failures BlockErrors
+		for _, f := range failed {
+			for _, idx := range requests[f.Index].indices {
+				failures = append(failures, &BlockError{Index: idx, Err: f.Err})
+			}
+		}
+		return results, failures
+	}
+
+	if d.Progress != nil {
+		_, _ = fmt.Fprintf(d.Progress, "Translated %d blocks.          \n", len(blocks))
+	}
+
+	return results, nil
+}
+
+// deeplBatch is one request: the indices of its blocks, and their tag
+// handling.
+type deeplBatch struct {
+	indices     []int
+	tagHandling string
+}
+
+// translateBatch sends one /v2/translate request.
+func (d *DeepLTranslator) translateBatch(ctx context.Context, texts []string, source, target, tagHandling string) ([]string, error) {
+	req := deeplRequest{
+		Text:        texts,
+		SourceLang:  deeplSourceLang(source),
+		TargetLang:  deeplTargetLang(target),
+		Formality:   d.Formality,
+		TagHandling: tagHandling,
+	}
+	headers := map[string]string{"Authorization": "DeepL-Auth-Key " + d.AuthKey}
+
+	var resp deeplResponse
+	url := strings.TrimRight(d.Endpoint, "/") + "/v2/translate"
+	if err := postJSON(ctx, d.Client, url, headers, req, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Translations) != len(texts) {
+		return nil, Permanent(fmt.Errorf("DeepL returned %d translations for %d texts", len(resp.Translations), len(texts)))
+	}
+
+	out := make([]string, len(texts))
+	for i, tr := range resp.Translations {
+		out[i] = tr.Text
+	}
+	return out, nil
+}
+
+// tagHandlingFor returns the tag_handling value to use for a block.
+func (d *DeepLTranslator) tagHandlingFor(block string) string {
+	if d.TagHandling != "auto" {
+		return d.TagHandling
+	}
+	if strings.HasPrefix(strings.TrimSpace(block), "<") {
+		return "html"
+	}
+	return ""
+}
+
+// deeplSourceLang converts a language code to a DeepL source language ("" lets DeepL detect it).
+// DeepL takes source languages without a region.
+func deeplSourceLang(code string) string {
+	if code == "" || code == "auto" {
+		return ""
+	}
+	base, _, _ := strings.Cut(strings.ToLower(strings.ReplaceAll(code, "_", "-")), "-")
+	if base == "no" {
+		return "NB"
+	}
+	return strings.ToUpper(base)
+}
+
+// deeplTargetLang converts a language code to a DeepL target language,
+// picking a regional variant where DeepL requires one.
+func deeplTargetLang(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(code, "_", "-"))
+	switch code {
+	case "en":
+		return "EN-US"
+	case "pt":
+		return "PT-PT"
+	case "no", "nb", "no-no", "nb-no":
+		return "NB"
+	case "zh", "zh-cn", "zh-sg", "zh-hans":
+		return "ZH-HANS"
+	case "zh-tw", "zh-hk", "zh-hant":
+		return "ZH-HANT"
+	}
+	return strings.ToUpper(code)
+}
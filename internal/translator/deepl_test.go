@@ -0,0 +1,162 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newDeepLStub returns a server that "translates" by prefixing the target
+// language and records each decoded request.
+func newDeepLStub(t *testing.T, requests *[]deeplRequest) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/translate" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "DeepL-Auth-Key secret:fx" {
+			http.Error(w, "bad auth "+got, http.StatusForbidden)
+			return
+		}
+		var req deeplRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		*requests = append(*requests, req)
+
+		var resp deeplResponse
+		for _, text := range req.Text {
+			resp.Translations = append(resp.Translations, struct {
+				DetectedSourceLanguage string `json:"detected_source_language"`
+				Text                   string `json:"text"`
+			}{req.SourceLang, req.TargetLang + ":" + text})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestDeepLTranslator_Translate(t *testing.T) {
+	var requests []deeplRequest
+	srv := newDeepLStub(t, &requests)
+	defer srv.Close()
+
+	d := NewDeepLTranslator("secret:fx", nil)
+	d.Endpoint = srv.URL
+	d.Formality = "more"
+
+	blocks := []string{"Bonjour", "", "<p>Salut</p>", "Merci"}
+	got, err := d.Translate(context.Background(), blocks, "fr", "es")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+
+	want := []string{"ES:Bonjour", "", "ES:<p>Salut</p>", "ES:Merci"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("block %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+
+	// Markdown and HTML blocks go in separate requests
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	if requests[0].TagHandling != "" || requests[1].TagHandling != "html" {
+		t.Errorf("unexpected tag handling: %q, %q", requests[0].TagHandling, requests[1].TagHandling)
+	}
+	if requests[0].SourceLang != "FR" || requests[0].Formality != "more" {
+		t.Errorf("unexpected request parameters: %+v", requests[0])
+	}
+}
+
+func TestDeepLTranslator_HTTPError(t *testing.T) {
+	var requests []deeplRequest
+	srv := newDeepLStub(t, &requests)
+	defer srv.Close()
+
+	d := NewDeepLTranslator("wrong", nil)
+	d.Endpoint = srv.URL
+
+	_, err := d.Translate(context.Background(), []string{"Bonjour", "", "Merci"}, "fr", "es")
+	if err == nil {
+		t.Fatal("expected error for rejected auth key")
+	}
+	if !strings.Contains(err.Error(), "403") {
+		t.Errorf("error should mention the HTTP status, got %q", err)
+	}
+	// The failure is reported for each block of the request.
+	var failed BlockErrors
+	if !errors.As(err, &failed) || len(failed) != 2 || failed[0].Index != 0 || failed[1].Index != 2 {
+		t.Errorf("expected errors for blocks 0 and 2, got %v", err)
+	}
+}
+
+func TestNewDeepLTranslator_Endpoint(t *testing.T) {
+	if got := NewDeepLTranslator("abc:fx", nil).Endpoint; got != DeepLFreeEndpoint {
+		t.Errorf("free key should use %s, got %s", DeepLFreeEndpoint, got)
+	}
+	if got := NewDeepLTranslator("abc", nil).Endpoint; got != DeepLProEndpoint {
+		t.Errorf("pro key should use %s, got %s", DeepLProEndpoint, got)
+	}
+}
+
+func TestDeepLEngine_Config(t *testing.T) {
+	t.Setenv("DEEPL_AUTH_KEY", "")
+	if _, err := New("deepl", Config{}); err == nil {
+		t.Error("deepl engine without auth key should fail")
+	}
+
+	tr, err := New("deepl", Config{Options: map[string]string{
+		"auth_key":  "abc",
+		"plan":      "free",
+		"formality": "less",
+	}})
+	if err != nil {
+		t.Fatalf("New(deepl) failed: %v", err)
+	}
+	d := tr.(*DeepLTranslator)
+	if d.Endpoint != DeepLFreeEndpoint || d.Formality != "less" {
+		t.Errorf("options not applied: %+v", d)
+	}
+
+	tr, err = New("deepl", Config{Options: map[string]string{"auth_key": "abc"}, Workers: 3, RateLimit: 2})
+	if err != nil {
+		t.Fatalf("New(deepl) failed: %v", err)
+	}
+	if c := tr.(*DeepLTranslator).Concurrency; c.Workers != 3 || c.RateLimit != 2 {
+		t.Errorf("--workers and --rate-limit not applied: %+v", c)
+	}
+}
+
+func TestDeepLLang(t *testing.T) {
+	tests := []struct {
+		code, source, target string
+	}{
+		{"es", "ES", "ES"},
+		{"de", "DE", "DE"},
+		{"en", "EN", "EN-US"},
+		{"en-GB", "EN", "EN-GB"},
+		{"pt", "PT", "PT-PT"},
+		{"pt-BR", "PT", "PT-BR"},
+		{"no", "NB", "NB"},
+		{"zh", "ZH", "ZH-HANS"},
+		{"zh-TW", "ZH", "ZH-HANT"},
+	}
+	for _, tt := range tests {
+		if got := deeplSourceLang(tt.code); got != tt.source {
+			t.Errorf("deeplSourceLang(%q) = %q, want %q", tt.code, got, tt.source)
+		}
+		if got := deeplTargetLang(tt.code); got != tt.target {
+			t.Errorf("deeplTargetLang(%q) = %q, want %q", tt.code, got, tt.target)
+		}
+	}
+	if got := deeplSourceLang("auto"); got != "" {
+		t.Errorf("deeplSourceLang(auto) = %q, want DeepL to detect it", got)
+	}
+}
@@ -0,0 +1,81 @@
+package translator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BlockError is the failure of a single block of a Translate call.
+type BlockError struct {
+	Index int // index of the block in the slice passed to Translate
+	Err   error
+}
+
+func (e *BlockError) Error() string {
+	return fmt.Sprintf("translating block %d: %v", e.Index, e.Err)
+}
+
+func (e *BlockError) Unwrap() error { return e.Err }
+
+// BlockErrors lists the blocks of a Translate call that failed, in the order
+// the failures occurred. Blocks that are neither translated nor listed were
+// not attempted, e.g. because the run was cancelled.
+type BlockErrors []*BlockError
+
+func (e BlockErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "no block failed"
+	case 1:
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%v (and %d more failed blocks)", e[0], len(e)-1)
+}
+
+func (e BlockErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, be := range e {
+		errs[i] = be
+	}
+	return errs
+}
+
+// Indices returns the indices of the failed blocks.
+func (e BlockErrors) Indices() []int {
+	indices := make([]int, len(e))
+	for i, be := range e {
+		indices[i] = be.Index
+	}
+	return indices
+}
+
+// remapErrors translates the block indices of a BlockErrors error returned
+// by an inner Translator into the indices of the caller, one inner block
+// standing for the outer blocks to(i). Other errors are returned unchanged.
+func remapErrors(err error, to func(i int) []int) error {
+	var errs BlockErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	var out BlockErrors
+	for _, be := range errs {
+		for _, idx := range to(be.Index) {
+			out = append(out, &BlockError{Index: idx, Err: be.Err})
+		}
+	}
+	if len(out) == 0 {
+		return err
+	}
+	return out
+}
+
+// indexIn returns a mapping for remapErrors from inner block i to
+// indices[i].
+func indexIn(indices []int) func(int) []int {
+	return func(i int) []int {
+		if i < 0 || i >= len(indices) {
+			return nil
+		}
+		return []int{indices[i]}
+	}
+}
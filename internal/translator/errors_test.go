@@ -0,0 +1,54 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBlockErrors_Error(t *testing.T) {
+	errs := BlockErrors{
+		{Index: 2, Err: errors.New("boom")},
+		{Index: 5, Err: context.Canceled},
+	}
+	if got := errs.Error(); got != "translating block 2: boom (and 1 more failed blocks)" {
+		t.Errorf("unexpected message %q", got)
+	}
+	if got := errs[:1].Error(); got != "translating block 2: boom" {
+		t.Errorf("unexpected message %q", got)
+	}
+	if !errors.Is(errs, context.Canceled) {
+		t.Error("BlockErrors should unwrap to the errors of its blocks")
+	}
+	if !slices.Equal(errs.Indices(), []int{2, 5}) {
+		t.Errorf("unexpected indices %v", errs.Indices())
+	}
+}
+
+func TestRemapErrors(t *testing.T) {
+	inner := BlockErrors{{Index: 1, Err: errors.New("boom")}}
+
+	var errs BlockErrors
+	err := remapErrors(inner, indexIn([]int{4, 7, 9}))
+	if !errors.As(err, &errs) || !slices.Equal(errs.Indices(), []int{7}) {
+		t.Errorf("expected block 7 to fail, got %v", err)
+	}
+
+	err = remapErrors(inner, func(i int) []int { return []int{10 * i, 10*i + 1} })
+	if !errors.As(err, &errs) || !slices.Equal(errs.Indices(), []int{10, 11}) {
+		t.Errorf("expected blocks 10 and 11 to fail, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "boom") {
+		t.Errorf("remapped error should keep the cause, got %v", err)
+	}
+
+	plain := errors.New("no blocks")
+	if got := remapErrors(plain, indexIn(nil)); got != plain {
+		t.Errorf("other errors should be returned unchanged, got %v", got)
+	}
+	if remapErrors(nil, indexIn(nil)) != nil {
+		t.Error("nil should stay nil")
+	}
+}
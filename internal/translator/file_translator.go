@@ -1,10 +1,12 @@
 package translator
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 
+	"bilingual_pdf/internal/align"
 	"bilingual_pdf/internal/parser"
 )
 
@@ -22,15 +24,23 @@ func NewFileTranslator(path string, warn io.Writer) *FileTranslator {
 	}
 }
 
-func (f *FileTranslator) Translate(blocks []string, source, target string) ([]string, error) {
-	data, err := os.ReadFile(f.Path)
-	if err != nil {
-		return nil, fmt.Errorf("reading translation file: %w", err)
-	}
+func init() {
+	Register(Engine{
+		Name:        "file",
+		Description: "pre-translated markdown file (--translation)",
+		New: func(cfg Config) (Translator, error) {
+			if cfg.Path == "" {
+				return nil, fmt.Errorf("requires --translation <file.md>")
+			}
+			return NewFileTranslator(cfg.Path, cfg.Warn), nil
+		},
+	})
+}
 
-	transBlocks, err := parser.Parse(data)
+func (f *FileTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	transBlocks, err := f.read(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("parsing translation file: %w", err)
+		return nil, err
 	}
 
 	srcCount := len(blocks)
@@ -56,17 +66,14 @@ func (f *FileTranslator) Translate(blocks []string, source, target string) ([]st
 	return results, nil
 }
 
-// TranslateBlocks translates parser.Block slices and returns translated Blocks
-// with HTML already rendered. This is used when we need the full Block info.
-func (f *FileTranslator) TranslateBlocks(sourceBlocks []parser.Block) ([]parser.Block, error) {
-	data, err := os.ReadFile(f.Path)
-	if err != nil {
-		return nil, fmt.Errorf("reading translation file: %w", err)
-	}
-
-	transBlocks, err := parser.Parse(data)
+// TranslateBlocks aligns the blocks of the translation file with the source
+// blocks (see align.Blocks) and returns both as the rows of the output, with
+// a zero Block where a side has no counterpart. The alignment decisions that
+// need checking are printed as warnings.
+func (f *FileTranslator) TranslateBlocks(ctx context.Context, sourceBlocks []parser.Block) ([]parser.Block, []parser.Block, error) {
+	transBlocks, err := f.read(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("parsing translation file: %w", err)
+		return nil, nil, err
 	}
 
 	srcCount := len(sourceBlocks)
@@ -76,18 +83,38 @@ func (f *FileTranslator) TranslateBlocks(sourceBlocks []parser.Block) ([]parser.
 		_, _ = fmt.Fprintf(f.Warn, "Warning: block count mismatch — source has %d blocks, translation has %d blocks\n", srcCount, tgtCount)
 	}
 
-	// Pad to the longer length
-	maxLen := srcCount
-	if tgtCount > maxLen {
-		maxLen = tgtCount
+	pairs := align.Blocks(sourceBlocks, transBlocks)
+	for _, note := range align.Report(pairs, sourceBlocks, transBlocks) {
+		_, _ = fmt.Fprintf(f.Warn, "Warning: alignment: %s\n", note)
 	}
 
-	result := make([]parser.Block, maxLen)
-	for i := 0; i < maxLen; i++ {
-		if i < tgtCount {
-			result[i] = transBlocks[i]
+	source := make([]parser.Block, len(pairs))
+	result := make([]parser.Block, len(pairs))
+	for i, p := range pairs {
+		if p.Source >= 0 {
+			source[i] = sourceBlocks[p.Source]
+		}
+		if p.Target >= 0 {
+			result[i] = transBlocks[p.Target]
 		}
 	}
 
-	return result, nil
+	return source, result, nil
+}
+
+// read parses the translation file, unless ctx is already done.
+func (f *FileTranslator) read(ctx context.Context) ([]parser.Block, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return nil, fmt.Errorf("reading translation file: %w", err)
+	}
+
+	transBlocks, err := parser.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing translation file: %w", err)
+	}
+	return transBlocks, ctx.Err()
 }
@@ -0,0 +1,62 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"bilingual_pdf/internal/glossary"
+	"bilingual_pdf/internal/placeholder"
+)
+
+// GlossaryTranslator enforces a glossary around the wrapped Translator:
+// glossary terms are masked before translation, replaced by their forced
+// target form afterwards, and a warning is printed for every term whose
+// expected counterpart is missing from the translation.
+type GlossaryTranslator struct {
+	Inner    Translator
+	Glossary *glossary.Glossary
+	Warn     io.Writer // where to print warnings (typically os.Stderr)
+}
+
+// NewGlossaryTranslator wraps inner with the given glossary.
+func NewGlossaryTranslator(inner Translator, g *glossary.Glossary, warn io.Writer) *GlossaryTranslator {
+	return &GlossaryTranslator{
+		Inner:    inner,
+		Glossary: g,
+		Warn:     warn,
+	}
+}
+
+func (g *GlossaryTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	masked := make([]string, len(blocks))
+	sets := make([]*placeholder.Set, len(blocks))
+	for i, block := range blocks {
+		sets[i] = placeholder.NewSet('G')
+		masked[i] = g.Glossary.Mask(block, sets[i])
+	}
+
+	// On failure, the blocks translated so far are restored and returned
+	// with the error.
+	translated, err := g.Inner.Translate(ctx, masked, source, target)
+
+	results := make([]string, max(len(translated), len(blocks)))
+	for i, text := range translated {
+		if i >= len(blocks) {
+			results[i] = text
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		results[i], _ = sets[i].Restore(text)
+		if blocks[i] == "" {
+			continue
+		}
+		for _, issue := range g.Glossary.Check(blocks[i], results[i]) {
+			_, _ = fmt.Fprintf(g.Warn, "Warning: block %d: glossary term %q has no %q in the translation\n",
+				i, issue.Term.Source, issue.Expected)
+		}
+	}
+	return results, err
+}
@@ -0,0 +1,59 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bilingual_pdf/internal/glossary"
+)
+
+// lossyTranslator uppercases text but drops the {{G1}} token.
+type lossyTranslator struct {
+	sent []string
+}
+
+func (l *lossyTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	l.sent = append(l.sent, blocks...)
+	results := make([]string, len(blocks))
+	for i, b := range blocks {
+		results[i] = strings.ReplaceAll(strings.ToUpper(b), "{{G1}}", "")
+	}
+	return results, nil
+}
+
+func TestGlossaryTranslator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glossary.csv")
+	if err := os.WriteFile(path, []byte("fr,es\nvin rouge,vino tinto\nbilingual_pdf,\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g, err := glossary.Load(path, "fr", "es")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	inner := &lossyTranslator{}
+	var warn bytes.Buffer
+	gt := NewGlossaryTranslator(inner, g, &warn)
+
+	got, err := gt.Translate(context.Background(), []string{"du vin rouge", "", "bilingual_pdf et vin rouge"}, "fr", "es")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+
+	if strings.Contains(inner.sent[0], "vin rouge") {
+		t.Errorf("glossary terms should be masked before translation, sent %q", inner.sent[0])
+	}
+	if got[0] != "DU vino tinto" {
+		t.Errorf("expected forced target term, got %q", got[0])
+	}
+	if got[2] != "bilingual_pdf ET " {
+		t.Errorf("unexpected block 2 %q", got[2])
+	}
+	if !strings.Contains(warn.String(), `block 2: glossary term "vin rouge"`) {
+		t.Errorf("expected a warning for the lost term, got %q", warn.String())
+	}
+}
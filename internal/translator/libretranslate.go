@@ -0,0 +1,144 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// DefaultLibreTranslateURL is the address of a locally running LibreTranslate server.
+const DefaultLibreTranslateURL = "http://localhost:5000"
+
+// LibreTranslator implements Translator using a LibreTranslate-compatible HTTP API,
+// typically a self-hosted instance.
+type LibreTranslator struct {
+	URL      string       // server base URL
+	APIKey   string       // optional API key
+	Format   string       // "auto" (HTML blocks as html), "html" or "text"
+	Client   *http.Client // if nil, http.DefaultClient is used
+	Progress io.Writer    // if non-nil, progress is printed here
+	Concurrency
+}
+
+// NewLibreTranslator creates a LibreTranslator for the given server URL.
+func NewLibreTranslator(url, apiKey string, progress io.Writer) *LibreTranslator {
+	return &LibreTranslator{
+		URL:         url,
+		APIKey:      apiKey,
+		Format:      "auto",
+		Progress:    progress,
+		Concurrency: Concurrency{Workers: 2, Retry: DefaultRetryPolicy, Timeout: DefaultTimeout},
+	}
+}
+
+func init() {
+	Register(Engine{
+		Name:        "libretranslate",
+		Description: "LibreTranslate server (URL from LIBRETRANSLATE_URL)",
+		BatchChars:  2000,
+		Options:     []string{"url", "api_key", "format"},
+		Secrets:     []string{"api_key"},
+		New: func(cfg Config) (Translator, error) {
+			url := cfg.Option("url", os.Getenv("LIBRETRANSLATE_URL"))
+			if url == "" {
+				url = DefaultLibreTranslateURL
+			}
+			l := NewLibreTranslator(url, cfg.Option("api_key", os.Getenv("LIBRETRANSLATE_API_KEY")), cfg.Progress)
+			l.Concurrency = cfg.concurrency(l.Concurrency)
+			switch l.Format = cfg.Option("format", l.Format); l.Format {
+			case "auto", "html", "text":
+			default:
+				return nil, fmt.Errorf("invalid format %q: must be auto, html or text", l.Format)
+			}
+			return l, nil
+		},
+	})
+}
+
+type libreTranslateRequest struct {
+	Q      string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+type libreTranslateResponse struct {
+	TranslatedText   string `json:"translatedText"`
+	DetectedLanguage *struct {
+		Confidence float64 `json:"confidence"`
+		Language   string  `json:"language"`
+	} `json:"detectedLanguage,omitempty"`
+}
+
+type libreDetectRequest struct {
+	Q      string `json:"q"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+type libreDetection struct {
+	Confidence float64 `json:"confidence"`
+	Language   string  `json:"language"`
+}
+
+func (l *LibreTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	if source == "" {
+		source = "auto"
+	}
+	return translateEach(ctx, blocks, l.Concurrency, l.Progress, func(ctx context.Context, _, _ int, block string) (string, error) {
+		req := libreTranslateRequest{
+			Q:      block,
+			Source: source,
+			Target: target,
+			Format: l.formatFor(block),
+			APIKey: l.APIKey,
+		}
+		var resp libreTranslateResponse
+		if err := postJSON(ctx, l.Client, l.endpoint("/translate"), nil, req, &resp); err != nil {
+			return "", err
+		}
+		return resp.TranslatedText, nil
+	})
+}
+
+// Detect asks the server for the language of text and returns the most
+// likely language code with its confidence (0 to 1).
+func (l *LibreTranslator) Detect(ctx context.Context, text string) (string, float64, error) {
+	ctx, cancel := withTimeout(ctx, l.Timeout)
+	defer cancel()
+
+	var resp []libreDetection
+	req := libreDetectRequest{Q: text, APIKey: l.APIKey}
+	if err := postJSON(ctx, l.Client, l.endpoint("/detect"), nil, req, &resp); err != nil {
+		return "", 0, fmt.Errorf("detecting language: %w", err)
+	}
+	if len(resp) == 0 {
+		return "", 0, fmt.Errorf("detecting language: empty response")
+	}
+	best := resp[0]
+	for _, d := range resp[1:] {
+		if d.Confidence > best.Confidence {
+			best = d
+		}
+	}
+	// The server reports the confidence as a percentage.
+	return best.Language, best.Confidence / 100, nil
+}
+
+func (l *LibreTranslator) endpoint(path string) string {
+	return strings.TrimRight(l.URL, "/") + path
+}
+
+// formatFor returns the LibreTranslate format to use for a block.
+func (l *LibreTranslator) formatFor(block string) string {
+	if l.Format != "auto" {
+		return l.Format
+	}
+	if strings.HasPrefix(strings.TrimSpace(block), "<") {
+		return "html"
+	}
+	return "text"
+}
@@ -0,0 +1,131 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func newLibreStub(t *testing.T, requests *[]libreTranslateRequest) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	mux := http.NewServeMux()
+	mux.HandleFunc("/translate", func(w http.ResponseWriter, r *http.Request) {
+		var req libreTranslateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.APIKey != "key" {
+			http.Error(w, `{"error":"Invalid API key"}`, http.StatusForbidden)
+			return
+		}
+		mu.Lock()
+		*requests = append(*requests, req)
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(libreTranslateResponse{TranslatedText: "[" + req.Target + "] " + req.Q})
+	})
+	mux.HandleFunc("/detect", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]libreDetection{
+			{Confidence: 40, Language: "es"},
+			{Confidence: 92, Language: "fr"},
+		})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestLibreTranslator_Translate(t *testing.T) {
+	var requests []libreTranslateRequest
+	srv := newLibreStub(t, &requests)
+	defer srv.Close()
+
+	l := NewLibreTranslator(srv.URL+"/", "key", nil)
+	got, err := l.Translate(context.Background(), []string{"Bonjour", "", "<p>Salut</p>"}, "fr", "es")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+
+	want := []string{"[es] Bonjour", "", "[es] <p>Salut</p>"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("block %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests (empty blocks skipped), got %d", len(requests))
+	}
+	for _, req := range requests {
+		want := "text"
+		if req.Q == "<p>Salut</p>" {
+			want = "html"
+		}
+		if req.Format != want {
+			t.Errorf("block %q: expected format %q, got %q", req.Q, want, req.Format)
+		}
+	}
+}
+
+func TestLibreTranslator_BatchedHTML(t *testing.T) {
+	var requests []libreTranslateRequest
+	srv := newLibreStub(t, &requests)
+	defer srv.Close()
+
+	bt := NewBatchTranslator(NewLibreTranslator(srv.URL, "key", nil), 2000, nil)
+	if _, err := bt.Translate(context.Background(), []string{"Bonjour", "Salut", "<p>Au revoir</p>"}, "fr", "es"); err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	for _, req := range requests {
+		if req.Q == "<p>Au revoir</p>" {
+			if req.Format != "html" {
+				t.Errorf("a batched HTML block should be sent as html, got %q", req.Format)
+			}
+			return
+		}
+	}
+	t.Errorf("the HTML block should be sent alone, got %+v", requests)
+}
+
+func TestLibreTranslator_InvalidKey(t *testing.T) {
+	var requests []libreTranslateRequest
+	srv := newLibreStub(t, &requests)
+	defer srv.Close()
+
+	l := NewLibreTranslator(srv.URL, "wrong", nil)
+	if _, err := l.Translate(context.Background(), []string{"Bonjour"}, "fr", "es"); err == nil {
+		t.Fatal("expected error for invalid API key")
+	}
+}
+
+func TestLibreTranslator_Detect(t *testing.T) {
+	var requests []libreTranslateRequest
+	srv := newLibreStub(t, &requests)
+	defer srv.Close()
+
+	l := NewLibreTranslator(srv.URL, "key", nil)
+	lang, confidence, err := l.Detect(context.Background(), "Bonjour le monde")
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if lang != "fr" || confidence != 0.92 {
+		t.Errorf("expected fr (0.92), got %s (%v)", lang, confidence)
+	}
+}
+
+func TestLibreTranslateEngine_Config(t *testing.T) {
+	t.Setenv("LIBRETRANSLATE_URL", "")
+	tr, err := New("libretranslate", Config{})
+	if err != nil {
+		t.Fatalf("New(libretranslate) failed: %v", err)
+	}
+	if got := tr.(*LibreTranslator).URL; got != DefaultLibreTranslateURL {
+		t.Errorf("expected default URL, got %q", got)
+	}
+
+	if _, err := New("libretranslate", Config{Options: map[string]string{"format": "pdf"}}); err == nil {
+		t.Error("invalid format should fail")
+	}
+}
@@ -0,0 +1,219 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"bilingual_pdf/internal/languages"
+)
+
+// LLM defaults, suitable for the OpenAI API.
+const (
+	DefaultLLMURL   = "https://api.openai.com/v1"
+	DefaultLLMModel = "gpt-4o-mini"
+)
+
+// DefaultLLMPrompt is the system prompt template used by LLMTranslator.
+// It is executed with an LLMPromptData value.
+const DefaultLLMPrompt = `You are a professional translator. Translate the user's text from {{.SourceName}} to {{.TargetName}}.
+{{- if .Tone}}
+Use a {{.Tone}} tone and register.
+{{- end}}
+The text is a Markdown fragment. Preserve all Markdown syntax exactly: heading and list markers, list numbering, line breaks, **emphasis** markers, links written as [text](url) with the url unchanged, ` + "`inline code`" + ` unchanged, HTML tags unchanged and placeholders such as {{"{{G0}}"}} unchanged. Translate only the human-readable text.
+Reply with the translation only, without comments, explanations or code fences.
+{{- if .Before}}
+
+For context only (do not translate it), the preceding text is:
+{{.Before}}
+{{- end}}
+{{- if .After}}
+
+For context only (do not translate it), the following text is:
+{{.After}}
+{{- end}}`
+
+// LLMPromptData is the data available to the system prompt template.
+type LLMPromptData struct {
+	SourceCode string // e.g. "fr"
+	TargetCode string // e.g. "es"
+	SourceName string // e.g. "French"
+	TargetName string // e.g. "Spanish"
+	Tone       string // tone/register instructions, may be empty
+	Before     string // preceding blocks, for context
+	After      string // following blocks, for context
+}
+
+// LLMTranslator implements Translator using an OpenAI-compatible
+// chat-completions API (OpenAI, Ollama, llama.cpp server, ...).
+type LLMTranslator struct {
+	URL         string             // API base URL, e.g. http://localhost:11434/v1
+	APIKey      string             // optional bearer token
+	Model       string             // model name
+	Prompt      *template.Template // system prompt template
+	Tone        string             // tone/register instructions passed to the prompt
+	Context     int                // number of surrounding blocks given as context
+	Temperature float64
+	Client      *http.Client // if nil, http.DefaultClient is used
+	Progress    io.Writer    // if non-nil, progress is printed here
+	Concurrency
+}
+
+// NewLLMTranslator creates an LLMTranslator with the default prompt.
+func NewLLMTranslator(url, apiKey, model string, progress io.Writer) *LLMTranslator {
+	return &LLMTranslator{
+		URL:         url,
+		APIKey:      apiKey,
+		Model:       model,
+		Prompt:      template.Must(template.New("prompt").Parse(DefaultLLMPrompt)),
+		Context:     1,
+		Progress:    progress,
+		Concurrency: Concurrency{Workers: 1, Retry: DefaultRetryPolicy, Timeout: DefaultTimeout},
+	}
+}
+
+func init() {
+	Register(Engine{
+		Name:        "llm",
+		Description: "OpenAI-compatible chat API (URL from OPENAI_BASE_URL)",
+		Options:     []string{"url", "api_key", "model", "tone", "prompt", "context", "temperature"},
+		Secrets:     []string{"api_key"},
+		Files:       []string{"prompt"},
+		New: func(cfg Config) (Translator, error) {
+			url := cfg.Option("url", os.Getenv("OPENAI_BASE_URL"))
+			if url == "" {
+				url = DefaultLLMURL
+			}
+			l := NewLLMTranslator(url, cfg.Option("api_key", os.Getenv("OPENAI_API_KEY")), cfg.Option("model", DefaultLLMModel), cfg.Progress)
+			l.Tone = cfg.Option("tone", "")
+			l.Concurrency = cfg.concurrency(l.Concurrency)
+
+			if path := cfg.Option("prompt", ""); path != "" {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return nil, fmt.Errorf("reading prompt template: %w", err)
+				}
+				tmpl, err := template.New("prompt").Parse(string(data))
+				if err != nil {
+					return nil, fmt.Errorf("parsing prompt template: %w", err)
+				}
+				l.Prompt = tmpl
+			}
+			if v := cfg.Option("context", ""); v != "" {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("invalid context %q: must be a non-negative integer", v)
+				}
+				l.Context = n
+			}
+			if v := cfg.Option("temperature", ""); v != "" {
+				f, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid temperature %q: %w", v, err)
+				}
+				l.Temperature = f
+			}
+			return l, nil
+		},
+	})
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (l *LLMTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	headers := map[string]string{}
+	if l.APIKey != "" {
+		headers["Authorization"] = "Bearer " + l.APIKey
+	}
+	url := strings.TrimRight(l.URL, "/") + "/chat/completions"
+
+	return translateEach(ctx, blocks, l.Concurrency, l.Progress, func(ctx context.Context, _, i int, block string) (string, error) {
+		prompt, err := l.systemPrompt(blocks, i, source, target)
+		if err != nil {
+			return "", err
+		}
+		req := chatRequest{
+			Model: l.Model,
+			Messages: []chatMessage{
+				{Role: "system", Content: prompt},
+				{Role: "user", Content: block},
+			},
+			Temperature: l.Temperature,
+		}
+		var resp chatResponse
+		if err := postJSON(ctx, l.Client, url, headers, req, &resp); err != nil {
+			return "", err
+		}
+		if len(resp.Choices) == 0 {
+			return "", fmt.Errorf("response has no choices")
+		}
+		return cleanLLMOutput(resp.Choices[0].Message.Content, block), nil
+	})
+}
+
+// systemPrompt renders the prompt template for block i.
+func (l *LLMTranslator) systemPrompt(blocks []string, i int, source, target string) (string, error) {
+	data := LLMPromptData{
+		SourceCode: source,
+		TargetCode: target,
+		SourceName: languages.Name(source),
+		TargetName: languages.Name(target),
+		Tone:       l.Tone,
+		Before:     contextBlocks(blocks, i, -1, l.Context),
+		After:      contextBlocks(blocks, i, +1, l.Context),
+	}
+	var buf strings.Builder
+	if err := l.Prompt.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering prompt template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// contextBlocks collects up to n non-empty blocks before (dir -1) or after
+// (dir +1) block i, in document order.
+func contextBlocks(blocks []string, i, dir, n int) string {
+	var found []string
+	for j := i + dir; j >= 0 && j < len(blocks) && len(found) < n; j += dir {
+		if blocks[j] != "" {
+			found = append(found, blocks[j])
+		}
+	}
+	if dir < 0 {
+		for a, b := 0, len(found)-1; a < b; a, b = a+1, b-1 {
+			found[a], found[b] = found[b], found[a]
+		}
+	}
+	return strings.Join(found, "\n\n")
+}
+
+// cleanLLMOutput removes wrapping that chat models tend to add around the
+// translation: surrounding whitespace and a code fence the source did not have.
+func cleanLLMOutput(out, source string) string {
+	out = strings.TrimSpace(out)
+	if strings.HasPrefix(out, "```") && !strings.HasPrefix(strings.TrimSpace(source), "```") {
+		if nl := strings.IndexByte(out, '\n'); nl >= 0 && strings.HasSuffix(out, "```") {
+			out = strings.TrimSpace(out[nl+1 : len(out)-3])
+		}
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newLLMStub returns a chat-completions server that wraps the user message
+// in a code fence (as chat models often do) and records the requests.
+func newLLMStub(t *testing.T, requests *[]chatRequest) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		*requests = append(*requests, req)
+
+		user := req.Messages[len(req.Messages)-1].Content
+		var resp chatResponse
+		resp.Choices = append(resp.Choices, struct {
+			Message chatMessage `json:"message"`
+		}{chatMessage{Role: "assistant", Content: "```markdown\n" + strings.ReplaceAll(user, "pain", "pan") + "\n```\n"}})
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestLLMTranslator_Translate(t *testing.T) {
+	var requests []chatRequest
+	srv := newLLMStub(t, &requests)
+	defer srv.Close()
+
+	l := NewLLMTranslator(srv.URL+"/v1", "token", "test-model", nil)
+	l.Tone = "formal"
+
+	blocks := []string{"# Courses", "", "- pain\n- [vin](https://example.com)"}
+	got, err := l.Translate(context.Background(), blocks, "fr", "es")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+
+	if got[1] != "" {
+		t.Errorf("empty block should stay empty, got %q", got[1])
+	}
+	if got[2] != "- pan\n- [vin](https://example.com)" {
+		t.Errorf("list markdown should be preserved without code fence, got %q", got[2])
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+
+	system := requests[1].Messages[0].Content
+	for _, want := range []string{"from French to Spanish", "formal", "# Courses"} {
+		if !strings.Contains(system, want) {
+			t.Errorf("system prompt should contain %q, got:\n%s", want, system)
+		}
+	}
+	if requests[1].Model != "test-model" {
+		t.Errorf("expected model test-model, got %q", requests[1].Model)
+	}
+}
+
+func TestLLMEngine_PromptTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt.tmpl")
+	if err := os.WriteFile(path, []byte("Translate {{.SourceCode}} to {{.TargetName}}."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tr, err := New("llm", Config{Options: map[string]string{"prompt": path, "context": "0"}})
+	if err != nil {
+		t.Fatalf("New(llm) failed: %v", err)
+	}
+	l := tr.(*LLMTranslator)
+	prompt, err := l.systemPrompt([]string{"a", "b"}, 1, "fr", "de")
+	if err != nil {
+		t.Fatalf("systemPrompt failed: %v", err)
+	}
+	if prompt != "Translate fr to German." {
+		t.Errorf("unexpected prompt %q", prompt)
+	}
+
+	if _, err := New("llm", Config{Options: map[string]string{"context": "-1"}}); err == nil {
+		t.Error("negative context should fail")
+	}
+}
+
+func TestContextBlocks(t *testing.T) {
+	blocks := []string{"a", "", "b", "c", "", "d"}
+	if got := contextBlocks(blocks, 3, -1, 2); got != "a\n\nb" {
+		t.Errorf("before: got %q", got)
+	}
+	if got := contextBlocks(blocks, 3, +1, 2); got != "d" {
+		t.Errorf("after: got %q", got)
+	}
+	if got := contextBlocks(blocks, 3, -1, 0); got != "" {
+		t.Errorf("no context: got %q", got)
+	}
+}
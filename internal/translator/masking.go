@@ -0,0 +1,64 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"bilingual_pdf/internal/mask"
+	"bilingual_pdf/internal/placeholder"
+)
+
+// MaskingTranslator protects markdown syntax around the wrapped Translator:
+// code spans, autolinks, link destinations, images, URLs and HTML tags are
+// replaced by placeholders before translation and restored afterwards. A
+// warning is printed for every block that lost a placeholder.
+type MaskingTranslator struct {
+	Inner Translator
+	Warn  io.Writer // where to print warnings (typically os.Stderr)
+}
+
+// NewMaskingTranslator wraps inner with markdown masking.
+func NewMaskingTranslator(inner Translator, warn io.Writer) *MaskingTranslator {
+	return &MaskingTranslator{Inner: inner, Warn: warn}
+}
+
+func (m *MaskingTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	masked := make([]string, len(blocks))
+	sets := make([]*placeholder.Set, len(blocks))
+	for i, block := range blocks {
+		sets[i] = placeholder.NewSet(mask.Prefix)
+		masked[i] = mask.Markdown(block, sets[i])
+	}
+
+	// On failure, the blocks translated so far are restored and returned
+	// with the error.
+	translated, err := m.Inner.Translate(ctx, masked, source, target)
+
+	results := make([]string, max(len(translated), len(blocks)))
+	for i, text := range translated {
+		if i >= len(blocks) {
+			results[i] = text
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		var missing []string
+		results[i], missing = mask.Restore(text, sets[i])
+		if len(missing) > 0 {
+			_, _ = fmt.Fprintf(m.Warn, "Warning: block %d: the translation lost %d protected fragment(s): %s\n",
+				i, len(missing), strings.Join(quoteAll(missing), ", "))
+		}
+	}
+	return results, err
+}
+
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return quoted
+}
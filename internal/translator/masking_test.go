@@ -0,0 +1,72 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMaskingTranslator(t *testing.T) {
+	inner := &lossyTranslator{}
+	var warn bytes.Buffer
+	mt := NewMaskingTranslator(inner, &warn)
+
+	blocks := []string{
+		"run `go test` and see [the docs](https://example.com/docs)",
+		"",
+		"press <kbd>enter</kbd>",
+	}
+	got, err := mt.Translate(context.Background(), blocks, "en", "fr")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+
+	if strings.Contains(inner.sent[0], "go test") || strings.Contains(inner.sent[0], "example.com") {
+		t.Errorf("code and link destinations should be masked, sent %q", inner.sent[0])
+	}
+	if got[0] != "RUN `go test` AND SEE [THE DOCS](https://example.com/docs)" {
+		t.Errorf("unexpected restored block %q", got[0])
+	}
+	if got[1] != "" {
+		t.Errorf("empty block should stay empty, got %q", got[1])
+	}
+
+	// lossyTranslator drops {{G1}}, not {{M1}}: nothing should be lost.
+	if got[2] != "PRESS <kbd>ENTER</kbd>" {
+		t.Errorf("unexpected restored HTML %q", got[2])
+	}
+	if warn.Len() != 0 {
+		t.Errorf("unexpected warnings: %s", warn.String())
+	}
+}
+
+func TestMaskingTranslator_LostPlaceholder(t *testing.T) {
+	var warn bytes.Buffer
+	mt := NewMaskingTranslator(dropToken{upperTranslator{}, "{{M1}}"}, &warn)
+
+	got, err := mt.Translate(context.Background(), []string{"use `a` or `b`"}, "en", "fr")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if got[0] != "USE `a` OR " {
+		t.Errorf("unexpected result %q", got[0])
+	}
+	if !strings.Contains(warn.String(), "block 0") || !strings.Contains(warn.String(), "`b`") {
+		t.Errorf("expected a warning about the lost fragment, got %q", warn.String())
+	}
+}
+
+// dropToken removes a token from the wrapped translator's output.
+type dropToken struct {
+	inner Translator
+	token string
+}
+
+func (d dropToken) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	out, err := d.inner.Translate(ctx, blocks, source, target)
+	for i := range out {
+		out[i] = strings.ReplaceAll(out[i], d.token, "")
+	}
+	return out, err
+}
@@ -0,0 +1,60 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"bilingual_pdf/internal/tmx"
+)
+
+// MemoryTranslator takes the translation of a block from a translation
+// memory, such as one imported from TMX files, and calls the wrapped
+// Translator only for the blocks the memory does not have.
+type MemoryTranslator struct {
+	Inner    Translator
+	Memory   *tmx.Memory
+	Progress io.Writer // if non-nil, match counts are printed here
+}
+
+// NewMemoryTranslator wraps inner with the given translation memory.
+func NewMemoryTranslator(inner Translator, memory *tmx.Memory, progress io.Writer) *MemoryTranslator {
+	return &MemoryTranslator{
+		Inner:    inner,
+		Memory:   memory,
+		Progress: progress,
+	}
+}
+
+func (m *MemoryTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	results := make([]string, len(blocks))
+	var rest []string
+	var restIndex []int
+	for i, block := range blocks {
+		if block == "" {
+			continue
+		}
+		if tr, ok := m.Memory.Lookup(block); ok {
+			results[i] = tr
+			continue
+		}
+		rest = append(rest, block)
+		restIndex = append(restIndex, i)
+	}
+
+	if m.Progress != nil {
+		_, _ = fmt.Fprintf(m.Progress, "Translation memory: %d matches, %d to translate\n",
+			countNonEmpty(blocks)-len(rest), len(rest))
+	}
+	if len(rest) == 0 {
+		return results, nil
+	}
+
+	translated, err := m.Inner.Translate(ctx, rest, source, target)
+	for j, i := range restIndex {
+		if j < len(translated) {
+			results[i] = translated[j]
+		}
+	}
+	return results, remapErrors(err, indexIn(restIndex))
+}
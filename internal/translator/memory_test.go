@@ -0,0 +1,51 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"bilingual_pdf/internal/tmx"
+)
+
+func TestMemoryTranslator(t *testing.T) {
+	mem := tmx.NewMemory()
+	mem.Add([]tmx.Pair{{Source: "un", Target: "uno"}})
+
+	inner := &countingTranslator{}
+	mt := NewMemoryTranslator(inner, mem, nil)
+	got, err := mt.Translate(context.Background(), []string{"un", "", "deux", "un "}, "fr", "es")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if !slices.Equal(got, []string{"uno", "", "DEUX", "uno"}) {
+		t.Errorf("unexpected results %q", got)
+	}
+	if len(inner.calls) != 1 || !slices.Equal(inner.calls[0], []string{"deux"}) {
+		t.Errorf("expected only the unmatched block to be translated, got %q", inner.calls)
+	}
+
+	// Nothing reaches the engine when the memory has every block.
+	if _, err := mt.Translate(context.Background(), []string{"un"}, "fr", "es"); err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if len(inner.calls) != 1 {
+		t.Errorf("expected no engine call, got %q", inner.calls)
+	}
+}
+
+func TestMemoryTranslator_RemapsErrors(t *testing.T) {
+	mem := tmx.NewMemory()
+	mem.Add([]tmx.Pair{{Source: "un", Target: "uno"}})
+
+	mt := NewMemoryTranslator(interruptedTranslator{n: 1}, mem, nil)
+	got, err := mt.Translate(context.Background(), []string{"un", "deux", "trois"}, "fr", "es")
+	var errs BlockErrors
+	if !errors.As(err, &errs) || !slices.Equal(errs.Indices(), []int{2}) {
+		t.Fatalf("expected block 2 to fail, got %v", err)
+	}
+	if !slices.Equal(got, []string{"uno", "DEUX", ""}) {
+		t.Errorf("unexpected results %q", got)
+	}
+}
@@ -0,0 +1,205 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds a single request to a translation engine.
+const DefaultTimeout = 60 * time.Second
+
+// Concurrency controls how an engine issues requests: how many at once, how
+// fast, how long each may take and how failed requests are retried.
+type Concurrency struct {
+	Workers   int           // maximum requests in flight (values below 1 mean 1)
+	RateLimit float64       // maximum requests per second, 0 for unlimited
+	Retry     RetryPolicy   // retries of transient failures
+	Timeout   time.Duration // per attempt, 0 for no limit
+}
+
+// withTimeout returns the context for one request: ctx bounded by timeout,
+// if positive.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// rateLimiter is a token bucket: it holds up to burst tokens, refilled at
+// rate tokens per second, and each request consumes one token.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+	sleep  func(time.Duration) // for tests; sleepContext if nil
+}
+
+// newRateLimiter returns a limiter allowing rate requests per second with
+// bursts of up to burst requests, or nil if rate is not positive.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// Wait blocks until a request may be issued, or until ctx is done and
+// returns its error. A nil limiter never blocks.
+func (r *rateLimiter) Wait(ctx context.Context) error {
+	if r == nil {
+		return ctx.Err()
+	}
+	r.mu.Lock()
+	now := r.now()
+	if !r.last.IsZero() {
+		r.tokens = min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.rate)
+	}
+	r.last = now
+
+	// Reserve a token; if none is available the balance goes negative and
+	// the caller sleeps until it has been refilled.
+	r.tokens--
+	var wait time.Duration
+	if r.tokens < 0 {
+		wait = time.Duration(-r.tokens / r.rate * float64(time.Second))
+	}
+	r.mu.Unlock()
+
+	if r.sleep != nil {
+		if wait > 0 {
+			r.sleep(wait)
+		}
+		return ctx.Err()
+	}
+	return sleepContext(ctx, wait)
+}
+
+// completionKey is the context key of the function told of the blocks a
+// Translator completes; see withCompletion.
+type completionKey struct{}
+
+// withCompletion returns a context that makes a Translator call fn with the
+// index and the translation of each block as soon as the block is done,
+// before the whole call returns, so that a long run can be checkpointed as
+// it goes. fn may be called from several goroutines at once. Translators
+// that change the blocks they pass on, such as BatchTranslator, give their
+// inner Translator a context of their own.
+func withCompletion(ctx context.Context, fn func(i int, text string)) context.Context {
+	return context.WithValue(ctx, completionKey{}, fn)
+}
+
+// completed tells the function of ctx, if any, that block i is translated.
+func completed(ctx context.Context, i int, text string) {
+	if fn, ok := ctx.Value(completionKey{}).(func(int, string)); ok {
+		fn(i, text)
+	}
+}
+
+// translateEach calls fn for every non-empty block using a bounded pool of
+// workers and a rate limiter, and returns the results in block order.
+// The worker index passed to fn lets engines keep per-worker clients, and
+// the context passed to fn is bounded by c.Timeout. Transient failures are
+// retried according to c.Retry. Progress is reported on progress as blocks
+// complete, and each block to the completion function of ctx, if any. The
+// first failure, or the end of ctx, stops the dispatch of
+// further blocks; the blocks translated so far are returned with a
+// BlockErrors error, or with the error of ctx if no block failed.
+func translateEach(ctx context.Context, blocks []string, c Concurrency, progress io.Writer, fn func(ctx context.Context, worker, i int, text string) (string, error)) ([]string, error) {
+	workers := max(c.Workers, 1)
+	limiter := newRateLimiter(c.RateLimit, workers)
+	results := make([]string, len(blocks))
+	total := 0
+	for _, block := range blocks {
+		if block != "" {
+			total++
+		}
+	}
+
+	jobs := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		done     int
+		failures BlockErrors
+		stop     = make(chan struct{})
+		stopOnce sync.Once
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := range jobs {
+				var text string
+				err := c.Retry.Do(ctx, func() error {
+					if err := limiter.Wait(ctx); err != nil {
+						return err
+					}
+					reqCtx, cancel := withTimeout(ctx, c.Timeout)
+					defer cancel()
+					var err error
+					text, err = fn(reqCtx, worker, i, blocks[i])
+					return err
+				})
+
+				mu.Lock()
+				if err != nil {
+					failures = append(failures, &BlockError{Index: i, Err: err})
+					stopOnce.Do(func() { close(stop) })
+				} else {
+					results[i] = text
+					done++
+					if progress != nil {
+						_, _ = fmt.Fprintf(progress, "Translating block %d/%d...\r", done, total)
+					}
+				}
+				mu.Unlock()
+				if err == nil {
+					completed(ctx, i, text)
+				}
+			}
+		}(w)
+	}
+
+dispatch:
+	for i, block := range blocks {
+		if block == "" {
+			continue
+		}
+		select {
+		case jobs <- i:
+		case <-stop:
+			break dispatch
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return results, failures
+	}
+	if done < total {
+		return results, ctx.Err()
+	}
+	if progress != nil {
+		_, _ = fmt.Fprintf(progress, "Translated %d blocks.          \n", len(blocks))
+	}
+	return results, nil
+}
@@ -0,0 +1,182 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTranslateEach_OrderAndConcurrency(t *testing.T) {
+	blocks := make([]string, 40)
+	for i := range blocks {
+		if i%5 != 0 {
+			blocks[i] = fmt.Sprintf("b%d", i)
+		}
+	}
+
+	var inFlight, maxInFlight int32
+	var progress strings.Builder
+	results, err := translateEach(context.Background(), blocks, Concurrency{Workers: 4}, &progress, func(_ context.Context, worker, i int, text string) (string, error) {
+		if worker < 0 || worker >= 4 {
+			t.Errorf("unexpected worker index %d", worker)
+		}
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return strings.ToUpper(text), nil
+	})
+	if err != nil {
+		t.Fatalf("translateEach failed: %v", err)
+	}
+
+	for i, b := range blocks {
+		if results[i] != strings.ToUpper(b) {
+			t.Errorf("block %d: expected %q, got %q", i, strings.ToUpper(b), results[i])
+		}
+	}
+	if maxInFlight > 4 {
+		t.Errorf("expected at most 4 requests in flight, got %d", maxInFlight)
+	}
+	if maxInFlight < 2 {
+		t.Errorf("expected requests to run concurrently, max in flight was %d", maxInFlight)
+	}
+	if !strings.Contains(progress.String(), "Translated 40 blocks.") {
+		t.Errorf("expected final progress line, got %q", progress.String())
+	}
+}
+
+func TestTranslateEach_Completion(t *testing.T) {
+	blocks := []string{"a", "", "c", "d"}
+	var mu sync.Mutex
+	got := map[int]string{}
+	ctx := withCompletion(context.Background(), func(i int, text string) {
+		mu.Lock()
+		defer mu.Unlock()
+		got[i] = text
+	})
+	_, err := translateEach(ctx, blocks, Concurrency{Workers: 2}, nil, func(_ context.Context, _, i int, text string) (string, error) {
+		if text == "d" {
+			return "", errors.New("boom")
+		}
+		return strings.ToUpper(text), nil
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(got) != 2 || got[0] != "A" || got[2] != "C" {
+		t.Errorf("expected the completed blocks 0 and 2 to be reported, got %v", got)
+	}
+}
+
+func TestTranslateEach_Error(t *testing.T) {
+	blocks := []string{"a", "b", "c", "d"}
+	var calls int32
+	_, err := translateEach(context.Background(), blocks, Concurrency{Workers: 1}, nil, func(_ context.Context, _, i int, text string) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		if i == 1 {
+			return "", errors.New("boom")
+		}
+		return text, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "translating block 1: boom") {
+		t.Fatalf("expected block 1 error, got %v", err)
+	}
+	if calls > 3 {
+		t.Errorf("dispatch should stop after the first error, got %d calls", calls)
+	}
+}
+
+func TestTranslateEach_Cancel(t *testing.T) {
+	blocks := []string{"a", "b", "c", "d", "e"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results, err := translateEach(ctx, blocks, Concurrency{Workers: 1}, nil, func(_ context.Context, _, i int, text string) (string, error) {
+		if i == 1 {
+			cancel()
+		}
+		return strings.ToUpper(text), nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancellation error, got %v", err)
+	}
+	if results[0] != "A" || results[1] != "B" {
+		t.Errorf("blocks translated before the cancellation should be returned, got %q", results)
+	}
+	if results[4] != "" {
+		t.Errorf("blocks after the cancellation should not be translated, got %q", results)
+	}
+}
+
+func TestTranslateEach_Timeout(t *testing.T) {
+	c := Concurrency{Workers: 1, Timeout: 10 * time.Millisecond, Retry: RetryPolicy{Attempts: 2}}
+	var calls int32
+	results, err := translateEach(context.Background(), []string{"slow", "fast"}, c, nil, func(ctx context.Context, _, i int, text string) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		if text == "slow" {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return strings.ToUpper(text), nil
+	})
+
+	var errs BlockErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Index != 0 {
+		t.Fatalf("expected block 0 to fail, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a deadline error, got %v", err)
+	}
+	if calls < 2 {
+		t.Errorf("a timed-out request should be retried, got %d calls", calls)
+	}
+	if results[0] != "" {
+		t.Errorf("failed block should be empty, got %q", results[0])
+	}
+}
+
+func TestRateLimiter_TokenBucket(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	var slept []time.Duration
+
+	r := newRateLimiter(10, 2) // 10 req/s, burst of 2
+	r.now = func() time.Time { return clock }
+	r.sleep = func(d time.Duration) {
+		mu.Lock()
+		slept = append(slept, d)
+		mu.Unlock()
+		clock = clock.Add(d)
+	}
+
+	for i := 0; i < 4; i++ {
+		_ = r.Wait(context.Background())
+	}
+
+	// The burst is free; each further request waits 100ms for a new token.
+	if len(slept) != 2 {
+		t.Fatalf("expected 2 waits, got %v", slept)
+	}
+	for _, d := range slept {
+		if d < 99*time.Millisecond || d > 101*time.Millisecond {
+			t.Errorf("expected ~100ms wait, got %v", d)
+		}
+	}
+
+	if newRateLimiter(0, 1) != nil {
+		t.Error("a zero rate should disable limiting")
+	}
+	var none *rateLimiter
+	_ = none.Wait(context.Background()) // must not block or panic
+}
@@ -0,0 +1,222 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultPseudoExpansion is the default extra length of pseudo-localized
+// text, typical of translations from English into European languages.
+const DefaultPseudoExpansion = 0.35
+
+// PseudoTranslator implements Translator with pseudo-localization: letters
+// are replaced by accented look-alikes, the text is lengthened and each
+// text segment is wrapped in brackets, so that layout problems and
+// untranslated strings show up without any network access. Markdown
+// syntax, placeholders, code spans, URLs and HTML tags are kept intact.
+// The output is deterministic.
+type PseudoTranslator struct {
+	Expansion float64 // extra length as a fraction of the text, e.g. 0.35 for +35%
+	Markers   bool    // wrap every text segment in [ ]
+}
+
+// NewPseudoTranslator creates a PseudoTranslator with the default expansion and markers.
+func NewPseudoTranslator() *PseudoTranslator {
+	return &PseudoTranslator{
+		Expansion: DefaultPseudoExpansion,
+		Markers:   true,
+	}
+}
+
+func init() {
+	Register(Engine{
+		Name:        "pseudo",
+		Description: "pseudo-localization for offline layout checks",
+		Options:     []string{"expansion", "markers"},
+		New: func(cfg Config) (Translator, error) {
+			p := NewPseudoTranslator()
+			if v := cfg.Option("expansion", ""); v != "" {
+				e, err := parseExpansion(v)
+				if err != nil {
+					return nil, err
+				}
+				p.Expansion = e
+			}
+			if v := cfg.Option("markers", ""); v != "" {
+				b, err := strconv.ParseBool(v)
+				if err != nil {
+					return nil, fmt.Errorf("invalid markers %q: must be true or false", v)
+				}
+				p.Markers = b
+			}
+			return p, nil
+		},
+	})
+}
+
+// parseExpansion accepts a fraction ("0.35") or a percentage ("35%").
+func parseExpansion(v string) (float64, error) {
+	s, percent := strings.CutSuffix(strings.TrimSpace(v), "%")
+	e, err := strconv.ParseFloat(s, 64)
+	if err == nil && percent {
+		e /= 100
+	}
+	if err != nil || e < 0 || e > 3 {
+		return 0, fmt.Errorf("invalid expansion %q: must be a fraction such as 0.35 or a percentage such as 35%%", v)
+	}
+	return e, nil
+}
+
+func (p *PseudoTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	results := make([]string, len(blocks))
+	for i, block := range blocks {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		if block != "" {
+			results[i] = p.Localize(block)
+		}
+	}
+	return results, nil
+}
+
+var (
+	// pseudoPrefixPattern matches the block syntax at the start of a line:
+	// indentation, list markers, blockquote markers and heading markers.
+	pseudoPrefixPattern = regexp.MustCompile(`^[ \t]*(?:(?:[-*+]|\d{1,9}[.)])[ \t]+|>[ \t]?|#{1,6}[ \t]+)*`)
+
+	// pseudoProtectedPattern matches inline fragments that must not change:
+	// placeholder tokens, code spans, HTML tags and entities, link
+	// destinations and URLs.
+	pseudoProtectedPattern = regexp.MustCompile("\\{\\{\\s*[A-Z]\\s*\\d+\\s*\\}\\}|`+[^`]*`+|<[^<>]+>|&#?[a-zA-Z0-9]+;|\\]\\([^)]*\\)|https?://[^\\s)]+")
+)
+
+// pseudoPadding supplies the words appended to lengthen a segment.
+const pseudoPadding = "ļöŕéɱ íþšûɱ ðöļöŕ šíţ áɱéţ çöñšéçţéţûŕ áðíþíšçíñĝ éļíţ šéð ðö éíûšɱöð ţéɱþöŕ "
+
+var pseudoLetters = map[rune]rune{
+	'a': 'á', 'b': 'ƀ', 'c': 'ç', 'd': 'ð', 'e': 'é', 'f': 'ƒ', 'g': 'ĝ', 'h': 'ĥ', 'i': 'í',
+	'j': 'ĵ', 'k': 'ķ', 'l': 'ļ', 'm': 'ɱ', 'n': 'ñ', 'o': 'ö', 'p': 'þ', 'q': 'ǫ', 'r': 'ŕ',
+	's': 'š', 't': 'ţ', 'u': 'û', 'v': 'ṽ', 'w': 'ŵ', 'x': 'ẋ', 'y': 'ý', 'z': 'ž',
+	'A': 'Á', 'B': 'Ɓ', 'C': 'Ç', 'D': 'Ð', 'E': 'É', 'F': 'Ƒ', 'G': 'Ĝ', 'H': 'Ĥ', 'I': 'Í',
+	'J': 'Ĵ', 'K': 'Ķ', 'L': 'Ļ', 'M': 'Ṁ', 'N': 'Ñ', 'O': 'Ö', 'P': 'Þ', 'Q': 'Ǫ', 'R': 'Ŕ',
+	'S': 'Š', 'T': 'Ţ', 'U': 'Û', 'V': 'Ṽ', 'W': 'Ŵ', 'X': 'Ẋ', 'Y': 'Ý', 'Z': 'Ž',
+}
+
+// Localize pseudo-localizes a block of markdown. A segment is a line that
+// starts a paragraph, list item, heading or quote, together with its
+// continuation lines; each segment is bracketed and padded as a whole.
+// Lines without translatable letters, such as lines of HTML tags, separate
+// segments.
+func (p *PseudoTranslator) Localize(text string) string {
+	lines := strings.Split(text, "\n")
+	prefixes := make([]string, len(lines))
+	contents := make([]string, len(lines))
+	letters := make([]int, len(lines))
+	for i, line := range lines {
+		prefixes[i] = pseudoPrefixPattern.FindString(line)
+		contents[i], letters[i] = accent(line[len(prefixes[i]):])
+	}
+
+	for start := 0; start < len(lines); {
+		if letters[start] == 0 {
+			start++
+			continue
+		}
+		end, total := start+1, letters[start]
+		for end < len(lines) && letters[end] > 0 && strings.TrimSpace(prefixes[end]) == "" {
+			total += letters[end]
+			end++
+		}
+		p.decorate(contents[start:end], total)
+		start = end
+	}
+
+	for i := range lines {
+		lines[i] = prefixes[i] + contents[i]
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	// pseudoLeadingPattern and pseudoTrailingPattern match the tags and
+	// placeholders around the text of a line, and pseudoLeadingTagPattern
+	// and pseudoTrailingTagPattern the tags alone; markers go inside them.
+	pseudoLeadingPattern     = regexp.MustCompile(`^(?:\s*(?:\{\{\s*[A-Z]\s*\d+\s*\}\}|<[^<>]+>))*\s*`)
+	pseudoTrailingPattern    = regexp.MustCompile(`\s*(?:(?:\{\{\s*[A-Z]\s*\d+\s*\}\}|<[^<>]+>)\s*)*$`)
+	pseudoLeadingTagPattern  = regexp.MustCompile(`^(?:\s*<[^<>]+>)*\s*`)
+	pseudoTrailingTagPattern = regexp.MustCompile(`\s*(?:<[^<>]+>\s*)*$`)
+	pseudoTokenPattern       = regexp.MustCompile(`\{\{\s*[A-Z]\s*\d+\s*\}\}`)
+)
+
+// decorate adds the markers and the padding for letters letters to the
+// accented lines of a segment, in place. The markers go inside the HTML
+// tags around the segment, and inside placeholders only if they enclose
+// its text without a space, as masked HTML tags do; other placeholders,
+// such as code spans or the destination and title of a link or image,
+// stay inside the markers.
+func (p *PseudoTranslator) decorate(contents []string, letters int) {
+	first, last := contents[0], contents[len(contents)-1]
+	i := len(pseudoLeadingPattern.FindString(first))
+	j := pseudoTrailingPattern.FindStringIndex(last)[0]
+	if !enclosesText(first[:i], last[j:]) {
+		i = len(pseudoLeadingTagPattern.FindString(first))
+		j = pseudoTrailingTagPattern.FindStringIndex(last)[0]
+	}
+
+	closing := ""
+	if pad := int(math.Round(float64(letters) * p.Expansion)); pad > 0 {
+		closing = " " + padding(pad)
+	}
+	if p.Markers {
+		closing += "]"
+	}
+	contents[len(contents)-1] = last[:j] + closing + last[j:]
+	if p.Markers {
+		contents[0] = contents[0][:i] + "[" + contents[0][i:]
+	}
+}
+
+// enclosesText reports whether the tags and placeholders before and after
+// the text of a segment hold placeholders on both sides, touching the text.
+func enclosesText(leading, trailing string) bool {
+	return pseudoTokenPattern.MatchString(leading) && pseudoTokenPattern.MatchString(trailing) &&
+		strings.TrimRight(leading, " \t") == leading && strings.TrimLeft(trailing, " \t") == trailing
+}
+
+// accent replaces the ASCII letters of text outside protected fragments and
+// returns the result with the number of letters seen.
+func accent(text string) (string, int) {
+	var buf strings.Builder
+	letters := 0
+	last := 0
+	for _, m := range append(pseudoProtectedPattern.FindAllStringIndex(text, -1), []int{len(text), len(text)}) {
+		for _, r := range text[last:m[0]] {
+			if unicode.IsLetter(r) {
+				letters++
+			}
+			if a, ok := pseudoLetters[r]; ok {
+				r = a
+			}
+			buf.WriteRune(r)
+		}
+		buf.WriteString(text[m[0]:m[1]])
+		last = m[1]
+	}
+	return buf.String(), letters
+}
+
+// padding returns n runes of filler words, without a trailing space.
+func padding(n int) string {
+	filler := []rune(pseudoPadding)
+	out := make([]rune, n)
+	for i := range out {
+		out[i] = filler[i%len(filler)]
+	}
+	return strings.TrimRight(string(out), " ")
+}
@@ -0,0 +1,109 @@
+package translator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPseudoTranslator_Localize(t *testing.T) {
+	p := &PseudoTranslator{Markers: true}
+	tests := []struct {
+		in, want string
+	}{
+		{"Hello world", "[Ĥéļļö ŵöŕļð]"},
+		{"- one\n- two", "- [öñé]\n- [ţŵö]"},
+		{"> quoted", "> [ǫûöţéð]"},
+		{"a long\nparagraph", "[á ļöñĝ\nþáŕáĝŕáþĥ]"},
+		{"Run `go test` now", "[Ŕûñ `go test` ñöŵ]"},
+		{"See [docs](https://example.com/docs)", "[Šéé [ðöçš](https://example.com/docs)]"},
+		{"Keep {{M0}} and <kbd>Enter</kbd>", "[Ķééþ {{M0}} áñð <kbd>Éñţéŕ]</kbd>"},
+		{"{{M0}}\n{{M1}}", "{{M0}}\n{{M1}}"},
+		{"1. first\n   more\n2. second", "1. [ƒíŕšţ\n   ɱöŕé]\n2. [šéçöñð]"},
+	}
+	for _, tt := range tests {
+		if got := p.Localize(tt.in); got != tt.want {
+			t.Errorf("Localize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPseudoTranslator_Expansion(t *testing.T) {
+	p := NewPseudoTranslator()
+	in := "The quick brown fox jumps over the lazy dog"
+	got := p.Localize(in)
+
+	if !strings.HasPrefix(got, "[Ţĥé ǫûíçķ") || !strings.HasSuffix(got, "]") {
+		t.Errorf("unexpected pseudo-localization %q", got)
+	}
+	ratio := float64(utf8.RuneCountInString(got)) / float64(utf8.RuneCountInString(in))
+	if ratio < 1.3 || ratio > 1.45 {
+		t.Errorf("expected +30-45%% length, got ratio %.2f: %q", ratio, got)
+	}
+	if again := p.Localize(in); again != got {
+		t.Errorf("output should be deterministic: %q != %q", again, got)
+	}
+}
+
+func TestPseudoEngine_Options(t *testing.T) {
+	tr, err := New("pseudo", Config{Options: map[string]string{"expansion": "40%", "markers": "false"}})
+	if err != nil {
+		t.Fatalf("New(pseudo) failed: %v", err)
+	}
+	p := tr.(*PseudoTranslator)
+	if p.Expansion != 0.4 || p.Markers {
+		t.Errorf("options not applied: %+v", p)
+	}
+
+	got, err := tr.Translate(context.Background(), []string{"Bonjour", ""}, "fr", "es")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if !strings.HasPrefix(got[0], "Ɓöñĵöûŕ ") || got[1] != "" {
+		t.Errorf("unexpected translation %q", got)
+	}
+
+	if _, err := New("pseudo", Config{Options: map[string]string{"expansion": "lots"}}); err == nil {
+		t.Error("invalid expansion should fail")
+	}
+}
+
+func TestPseudoTranslator_MarkersInsideTags(t *testing.T) {
+	p := &PseudoTranslator{Markers: true}
+	tests := []struct {
+		in, want string
+	}{
+		{"<div>\n<p>Hello <b>you</b>.</p>\n</div>", "<div>\n<p>[Ĥéļļö <b>ýöû</b>.]</p>\n</div>"},
+		{"{{M0}}\n{{M1}}Hello{{M2}}\n{{M3}}", "{{M0}}\n{{M1}}[Ĥéļļö]{{M2}}\n{{M3}}"},
+		{"Read [the docs]{{M0}}", "[Ŕéáð [ţĥé ðöçš]{{M0}}]"},
+	}
+	for _, tt := range tests {
+		if got := p.Localize(tt.in); got != tt.want {
+			t.Errorf("Localize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPseudoTranslator_MarkersOutsideSyntax(t *testing.T) {
+	tr := NewMaskingTranslator(&PseudoTranslator{Markers: true}, nil)
+	blocks := []string{
+		`![Un schéma](img.png "Le titre")`,
+		`Voir [le site](https://example.com "Accueil")`,
+		"Le titre `code`",
+	}
+	want := []string{
+		`[![Ûñ šçĥéɱá](img.png "Ļé ţíţŕé")]`,
+		`[Ṽöíŕ [ļé šíţé](https://example.com "Accueil")]`,
+		"[Ļé ţíţŕé `code`]",
+	}
+	got, err := tr.Translate(context.Background(), blocks, "fr", "es")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("block %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package translator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"bilingual_pdf/internal/parser"
+)
+
+// DefaultEngine is the engine used when none is specified.
+const DefaultEngine = "google"
+
+// Config holds the settings passed to an engine factory.
+type Config struct {
+	Progress io.Writer         // if non-nil, progress is printed here
+	Warn     io.Writer         // where to print warnings (typically os.Stderr)
+	Path     string            // pre-translated file, for file-based engines
+	Options  map[string]string // engine-specific options (--engine-opt key=value)
+
+	// Workers and RateLimit override the engine's defaults when positive.
+	Workers   int
+	RateLimit float64
+	// Retries is the number of extra attempts after a transient failure.
+	Retries int
+	// Timeout overrides the engine's time limit for a single request when positive.
+	Timeout time.Duration
+}
+
+// concurrency returns def with the configured overrides applied.
+func (c Config) concurrency(def Concurrency) Concurrency {
+	if c.Workers > 0 {
+		def.Workers = c.Workers
+	}
+	if c.RateLimit > 0 {
+		def.RateLimit = c.RateLimit
+	}
+	if c.Timeout > 0 {
+		def.Timeout = c.Timeout
+	}
+	def.Retry.Attempts = c.Retries + 1
+	return def
+}
+
+// Option returns the engine-specific option for key, or def if it is unset.
+func (c Config) Option(key, def string) string {
+	if v, ok := c.Options[key]; ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// Factory builds a Translator from a Config.
+type Factory func(cfg Config) (Translator, error)
+
+// Engine describes a named translation backend.
+type Engine struct {
+	Name        string
+	Description string
+	Options     []string // option keys understood by the engine, for --list-engines
+	New         Factory
+
+	// Secrets are the options holding credentials, and Files those naming
+	// a file the engine reads, such as a prompt template; see CacheOptions.
+	Secrets []string
+	Files   []string
+
+	// BatchChars is the default maximum size in characters of a request
+	// packing several blocks (see BatchTranslator); 0 means the engine is
+	// sent one block per request, e.g. because it batches on its own.
+	BatchChars int
+}
+
+// BlockTranslator is implemented by engines that produce complete translated
+// blocks instead of text (e.g. a pre-translated markdown file). The source
+// and translated blocks are returned as the rows of the output, which may
+// have gaps (zero Blocks) on either side.
+type BlockTranslator interface {
+	TranslateBlocks(ctx context.Context, sourceBlocks []parser.Block) (source, translated []parser.Block, err error)
+}
+
+// Detector is implemented by engines that can identify the language of a
+// text. The confidence is between 0 and 1.
+type Detector interface {
+	Detect(ctx context.Context, text string) (lang string, confidence float64, err error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Engine{}
+)
+
+// Register makes an engine available by name. It panics if the name is empty,
+// the factory is nil or the name is already registered.
+func Register(e Engine) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if e.Name == "" || e.New == nil {
+		panic("translator: Register requires a name and a factory")
+	}
+	if _, dup := registry[e.Name]; dup {
+		panic("translator: Register called twice for engine " + e.Name)
+	}
+	registry[e.Name] = e
+}
+
+// Lookup returns the engine registered under name.
+func Lookup(name string) (Engine, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	e, ok := registry[name]
+	if !ok {
+		return Engine{}, fmt.Errorf("unknown engine %q (use --list-engines to see available engines)", name)
+	}
+	return e, nil
+}
+
+// CheckOptions returns an error naming the options the engine does not
+// understand, such as a misspelled key, and the options it does.
+func (e Engine) CheckOptions(options map[string]string) error {
+	var unknown []string
+	for key := range options {
+		if !slices.Contains(e.Options, key) {
+			unknown = append(unknown, strconv.Quote(key))
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	if len(e.Options) == 0 {
+		return fmt.Errorf("engine %s takes no options, got %s", e.Name, strings.Join(unknown, ", "))
+	}
+	return fmt.Errorf("engine %s: unknown option %s (valid options: %s)", e.Name, strings.Join(unknown, ", "), strings.Join(e.Options, ", "))
+}
+
+// CacheOptions returns the options that decide the translations of the
+// engine, to key its cache: credentials are left out, so that rotating a
+// key keeps the cache, and files are replaced by a hash of their content,
+// so that editing one misses it. Unset options are left out.
+func (e Engine) CacheOptions(options map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(options))
+	for key, v := range options {
+		if v == "" || slices.Contains(e.Secrets, key) {
+			continue
+		}
+		if slices.Contains(e.Files, key) {
+			data, err := os.ReadFile(v)
+			if err != nil {
+				return nil, fmt.Errorf("engine %s: option %s: %w", e.Name, key, err)
+			}
+			sum := sha256.Sum256(data)
+			v = "sha256:" + hex.EncodeToString(sum[:])
+		}
+		out[key] = v
+	}
+	return out, nil
+}
+
+// New builds the Translator for the named engine. It fails on options the
+// engine does not understand.
+func New(name string, cfg Config) (Translator, error) {
+	e, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.CheckOptions(cfg.Options); err != nil {
+		return nil, err
+	}
+	t, err := e.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("engine %s: %w", name, err)
+	}
+	return t, nil
+}
+
+// Engines returns the registered engines sorted by name.
+func Engines() []Engine {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	engines := make([]Engine, 0, len(registry))
+	for _, e := range registry {
+		engines = append(engines, e)
+	}
+	sort.Slice(engines, func(i, j int) bool {
+		return engines[i].Name < engines[j].Name
+	})
+	return engines
+}
+
+// PrintEngines writes the registered engines table to the given writer.
+func PrintEngines(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Engine\tDescription\tOptions")
+	_, _ = fmt.Fprintln(tw, "------\t-----------\t-------")
+	for _, e := range Engines() {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", e.Name, e.Description, strings.Join(e.Options, ", "))
+	}
+	_ = tw.Flush()
+}
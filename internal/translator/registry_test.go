@@ -0,0 +1,155 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type upperTranslator struct{}
+
+func (upperTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	results := make([]string, len(blocks))
+	for i, b := range blocks {
+		results[i] = strings.ToUpper(b)
+	}
+	return results, nil
+}
+
+func TestRegistry_BuiltinEngines(t *testing.T) {
+	for _, name := range []string{"google", "file"} {
+		if _, err := Lookup(name); err != nil {
+			t.Errorf("Lookup(%q) failed: %v", name, err)
+		}
+	}
+	if _, err := Lookup(DefaultEngine); err != nil {
+		t.Errorf("default engine %q is not registered: %v", DefaultEngine, err)
+	}
+}
+
+func TestRegistry_UnknownEngine(t *testing.T) {
+	_, err := Lookup("no-such-engine")
+	if err == nil {
+		t.Fatal("expected error for unknown engine")
+	}
+	if !strings.Contains(err.Error(), "--list-engines") {
+		t.Errorf("error should point to --list-engines, got %q", err)
+	}
+}
+
+func TestRegistry_RegisterAndNew(t *testing.T) {
+	Register(Engine{
+		Name:        "test-upper",
+		Description: "uppercases text",
+		Options:     []string{"mode"},
+		New: func(cfg Config) (Translator, error) {
+			if cfg.Option("mode", "upper") != "upper" {
+				t.Errorf("unexpected mode option %q", cfg.Option("mode", ""))
+			}
+			return upperTranslator{}, nil
+		},
+	})
+
+	tr, err := New("test-upper", Config{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	got, err := tr.Translate(context.Background(), []string{"hola"}, "es", "en")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if got[0] != "HOLA" {
+		t.Errorf("expected HOLA, got %q", got[0])
+	}
+
+	var buf bytes.Buffer
+	PrintEngines(&buf)
+	if !strings.Contains(buf.String(), "test-upper") || !strings.Contains(buf.String(), "mode") {
+		t.Errorf("PrintEngines should list the registered engine and its options, got:\n%s", buf.String())
+	}
+}
+
+func TestRegistry_UnknownOption(t *testing.T) {
+	_, err := New("deepl", Config{Options: map[string]string{"auth_key": "k", "formaility": "less"}})
+	if err == nil {
+		t.Fatal("a misspelled option should fail")
+	}
+	for _, want := range []string{`"formaility"`, "formality", "tag_handling"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should name %s, got %q", want, err)
+		}
+	}
+
+	if _, err := New("google", Config{Options: map[string]string{"model": "x"}}); err == nil || !strings.Contains(err.Error(), "no options") {
+		t.Errorf("an engine without options should reject them, got %v", err)
+	}
+	if _, err := New("pseudo", Config{Options: map[string]string{"markers": "false"}}); err != nil {
+		t.Errorf("a known option should be accepted, got %v", err)
+	}
+}
+
+func TestEngine_CacheOptions(t *testing.T) {
+	e, err := Lookup("llm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prompt := filepath.Join(t.TempDir(), "prompt.tmpl")
+	if err := os.WriteFile(prompt, []byte("Translate."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	options := func(key string) map[string]string {
+		o, err := e.CacheOptions(map[string]string{"api_key": key, "model": "m", "prompt": prompt, "tone": ""})
+		if err != nil {
+			t.Fatalf("CacheOptions failed: %v", err)
+		}
+		return o
+	}
+
+	first := options("old")
+	if _, ok := first["api_key"]; ok {
+		t.Error("credentials should not be part of the cache key")
+	}
+	if _, ok := first["tone"]; ok {
+		t.Error("unset options should be left out")
+	}
+	if !maps.Equal(first, options("new")) {
+		t.Error("rotating the key should keep the cache")
+	}
+	if err := os.WriteFile(prompt, []byte("Translate formally."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if edited := options("old"); edited["prompt"] == first["prompt"] {
+		t.Error("editing the prompt template should change the key")
+	}
+
+	if _, err := e.CacheOptions(map[string]string{"prompt": filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("a missing prompt file should fail")
+	}
+}
+
+func TestRegistry_DuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a duplicate engine should panic")
+		}
+	}()
+	Register(Engine{Name: "google", New: func(Config) (Translator, error) { return nil, nil }})
+}
+
+func TestRegistry_FileEngineRequiresPath(t *testing.T) {
+	if _, err := New("file", Config{}); err == nil {
+		t.Error("file engine without a path should fail")
+	}
+
+	tr, err := New("file", Config{Path: "../../testdata/sample.es.md"})
+	if err != nil {
+		t.Fatalf("New(file) failed: %v", err)
+	}
+	if _, ok := tr.(BlockTranslator); !ok {
+		t.Error("file engine should implement BlockTranslator")
+	}
+}
@@ -0,0 +1,133 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried: with exponential
+// backoff and jitter, and only for errors classified as transient.
+type RetryPolicy struct {
+	Attempts  int           // total attempts per request (values below 1 mean 1)
+	BaseDelay time.Duration // delay before the first retry, doubled for each further retry
+	MaxDelay  time.Duration // upper bound for a single delay
+
+	sleep func(time.Duration) // for tests; sleepContext if nil
+}
+
+// DefaultRetryPolicy retries transient failures three times.
+var DefaultRetryPolicy = RetryPolicy{Attempts: 4, BaseDelay: 500 * time.Millisecond, MaxDelay: 15 * time.Second}
+
+// Do calls fn until it succeeds, returns a permanent error or the attempts
+// are exhausted, and returns the last error. It stops retrying as soon as
+// ctx is done.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	attempts := max(p.Attempts, 1)
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt == attempts-1 || !IsRetryable(err) {
+			break
+		}
+		if p.sleep != nil {
+			p.sleep(p.delay(attempt, err))
+		} else if err := sleepContext(ctx, p.delay(attempt, err)); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// sleepContext waits for d, or until ctx is done and returns its error.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// delay returns the wait before retry number attempt+1: the server's
+// Retry-After if given, otherwise exponential backoff with equal jitter.
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+		return httpErr.RetryAfter
+	}
+
+	d := p.BaseDelay << attempt
+	if p.MaxDelay > 0 && (d > p.MaxDelay || d <= 0) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// permanentError marks an error that must not be retried.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that it is never retried.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+// IsRetryable reports whether err is likely transient: rate limiting, server
+// errors and network failures. HTTP client errors such as a rejected API key
+// or an unsupported language are permanent. Errors that cannot be classified
+// (e.g. from the Google web API) are treated as transient.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var perm permanentError
+	if errors.As(err, &perm) {
+		return false
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		switch {
+		case httpErr.StatusCode == http.StatusRequestTimeout,
+			httpErr.StatusCode == http.StatusTooEarly,
+			httpErr.StatusCode == http.StatusTooManyRequests:
+			return true
+		case httpErr.StatusCode >= 500:
+			return httpErr.StatusCode != http.StatusNotImplemented
+		default:
+			return false
+		}
+	}
+
+	// A cancelled run is never retried; a request that ran out of time is.
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// Network failures (timeouts, reset or refused connections) and errors
+	// that cannot be classified are worth another attempt.
+	return true
+}
@@ -0,0 +1,134 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_RetriesTransientErrors(t *testing.T) {
+	var delays []time.Duration
+	p := RetryPolicy{Attempts: 4, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	p.sleep = func(d time.Duration) { delays = append(delays, d) }
+
+	calls := 0
+	err := p.Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return &HTTPError{StatusCode: http.StatusServiceUnavailable}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if calls != 3 || len(delays) != 2 {
+		t.Fatalf("expected 3 calls and 2 delays, got %d calls and %v", calls, delays)
+	}
+
+	// Exponential backoff with equal jitter: [base/2, base], then [base, 2*base]
+	if delays[0] < 50*time.Millisecond || delays[0] > 100*time.Millisecond {
+		t.Errorf("first delay %v out of range", delays[0])
+	}
+	if delays[1] < 100*time.Millisecond || delays[1] > 200*time.Millisecond {
+		t.Errorf("second delay %v out of range", delays[1])
+	}
+}
+
+func TestRetryPolicy_StopsOnPermanentError(t *testing.T) {
+	p := RetryPolicy{Attempts: 5}
+	p.sleep = func(time.Duration) {}
+
+	calls := 0
+	err := p.Do(context.Background(), func() error {
+		calls++
+		return &HTTPError{StatusCode: http.StatusForbidden}
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("permanent error should not be retried: %d calls, err %v", calls, err)
+	}
+}
+
+func TestRetryPolicy_GivesUp(t *testing.T) {
+	p := RetryPolicy{Attempts: 3}
+	p.sleep = func(time.Duration) {}
+
+	calls := 0
+	err := p.Do(context.Background(), func() error {
+		calls++
+		return errors.New("connection reset")
+	})
+	if err == nil || calls != 3 {
+		t.Errorf("expected 3 attempts and an error, got %d calls, err %v", calls, err)
+	}
+}
+
+func TestRetryPolicy_StopsWhenCancelled(t *testing.T) {
+	p := RetryPolicy{Attempts: 5, BaseDelay: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	err := p.Do(ctx, func() error {
+		calls++
+		cancel()
+		return errors.New("connection reset")
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("expected 1 call and a cancellation error, got %d calls, err %v", calls, err)
+	}
+}
+
+func TestRetryPolicy_RetryAfter(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Millisecond}
+	got := p.delay(0, fmt.Errorf("wrapped: %w", &HTTPError{StatusCode: 429, RetryAfter: 3 * time.Second}))
+	if got != 3*time.Second {
+		t.Errorf("expected Retry-After delay of 3s, got %v", got)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&HTTPError{StatusCode: 429}, true},
+		{&HTTPError{StatusCode: 500}, true},
+		{&HTTPError{StatusCode: 502}, true},
+		{&HTTPError{StatusCode: 501}, false},
+		{&HTTPError{StatusCode: 400}, false},
+		{&HTTPError{StatusCode: 401}, false},
+		{fmt.Errorf("block 3: %w", &HTTPError{StatusCode: 503}), true},
+		{Permanent(errors.New("bad request")), false},
+		{errors.New("unexpected response"), true},
+		{context.Canceled, false},
+		{context.DeadlineExceeded, true},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestPostJSON_RetryAfterHeader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	var out struct{}
+	err := postJSON(context.Background(), nil, srv.URL, nil, struct{}{}, &out)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("expected HTTPError, got %v", err)
+	}
+	if httpErr.StatusCode != 429 || httpErr.RetryAfter != 7*time.Second {
+		t.Errorf("unexpected error %+v", httpErr)
+	}
+}
@@ -1,61 +1,82 @@
 package translator
 
 import (
-	"fmt"
+	"context"
 	"io"
-	"time"
 
 	googletrans "github.com/Conight/go-googletrans"
 )
 
 // Translator translates a slice of text blocks from source to target language.
+//
+// Translate returns one result per block, empty blocks giving empty results.
+// It stops when ctx is cancelled or its deadline expires. On failure it
+// returns the blocks translated so far along with the error; blocks that
+// were not translated are left empty, and a BlockErrors error tells which
+// blocks failed and why.
 type Translator interface {
-	Translate(blocks []string, source, target string) ([]string, error)
+	Translate(ctx context.Context, blocks []string, source, target string) ([]string, error)
 }
 
 // GoogleTranslator implements Translator using the free Google Translate API.
 type GoogleTranslator struct {
-	Delay    time.Duration // delay between API calls for rate limiting
-	Progress io.Writer     // if non-nil, progress is printed here
+	Concurrency           // worker pool size, rate limit and request timeout
+	Progress    io.Writer // if non-nil, progress is printed here
 }
 
 // NewGoogleTranslator creates a GoogleTranslator with sensible defaults.
 func NewGoogleTranslator(progress io.Writer) *GoogleTranslator {
 	return &GoogleTranslator{
-		Delay:    100 * time.Millisecond,
-		Progress: progress,
+		Concurrency: Concurrency{Workers: 4, RateLimit: 10, Retry: DefaultRetryPolicy, Timeout: DefaultTimeout},
+		Progress:    progress,
 	}
 }
 
-func (g *GoogleTranslator) Translate(blocks []string, source, target string) ([]string, error) {
-	t := googletrans.New()
-	results := make([]string, len(blocks))
-
-	for i, block := range blocks {
-		if block == "" {
-			results[i] = ""
-			continue
-		}
+func init() {
+	Register(Engine{
+		Name:        "google",
+		Description: "Google Translate (free web API)",
+		BatchChars:  4500,
+		New: func(cfg Config) (Translator, error) {
+			g := NewGoogleTranslator(cfg.Progress)
+			g.Concurrency = cfg.concurrency(g.Concurrency)
+			return g, nil
+		},
+	})
+}
 
-		if g.Progress != nil {
-			_, _ = fmt.Fprintf(g.Progress, "Translating block %d/%d...\r", i+1, len(blocks))
-		}
+func (g *GoogleTranslator) Translate(ctx context.Context, blocks []string, source, target string) ([]string, error) {
+	// The googletrans client caches its token without locking, so each
+	// worker gets its own.
+	clients := make([]*googletrans.Translator, max(g.Workers, 1))
+	for i := range clients {
+		clients[i] = googletrans.New()
+	}
 
-		result, err := t.Translate(block, source, target)
-		if err != nil {
-			return nil, fmt.Errorf("translating block %d: %w", i, err)
+	return translateEach(ctx, blocks, g.Concurrency, g.Progress, func(ctx context.Context, worker, _ int, text string) (string, error) {
+		// The client takes no context, so a request is abandoned rather than
+		// aborted when ctx is done, and the worker gets a fresh client.
+		type reply struct {
+			text string
+			err  error
 		}
-		results[i] = result.Text
+		client := clients[worker]
+		done := make(chan reply, 1)
+		go func() {
+			result, err := client.Translate(text, source, target)
+			if err != nil {
+				done <- reply{err: err}
+				return
+			}
+			done <- reply{text: result.Text}
+		}()
 
-		// Rate limiting delay between requests
-		if i < len(blocks)-1 && g.Delay > 0 {
-			time.Sleep(g.Delay)
+		select {
+		case r := <-done:
+			return r.text, r.err
+		case <-ctx.Done():
+			clients[worker] = googletrans.New()
+			return "", ctx.Err()
 		}
-	}
-
-	if g.Progress != nil {
-		_, _ = fmt.Fprintf(g.Progress, "Translated %d blocks.          \n", len(blocks))
-	}
-
-	return results, nil
+	})
 }
@@ -2,7 +2,10 @@ package translator
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -19,7 +22,7 @@ func TestFileTranslator_MatchingBlocks(t *testing.T) {
 		"Les saisons",
 	}
 
-	results, err := ft.Translate(sourceBlocks, "fr", "es")
+	results, err := ft.Translate(context.Background(), sourceBlocks, "fr", "es")
 	if err != nil {
 		t.Fatalf("Translate failed: %v", err)
 	}
@@ -45,7 +48,7 @@ func TestFileTranslator_MismatchedBlocks(t *testing.T) {
 		sourceBlocks[i] = "block"
 	}
 
-	results, err := ft.Translate(sourceBlocks, "fr", "es")
+	results, err := ft.Translate(context.Background(), sourceBlocks, "fr", "es")
 	if err != nil {
 		t.Fatalf("Translate failed: %v", err)
 	}
@@ -87,7 +90,7 @@ func TestFileTranslator_HTMLBlock(t *testing.T) {
 		t.Fatalf("parsing source: %v", err)
 	}
 
-	transBlocks, err := ft.TranslateBlocks(sourceBlocks)
+	_, transBlocks, err := ft.TranslateBlocks(context.Background(), sourceBlocks)
 	if err != nil {
 		t.Fatalf("TranslateBlocks failed: %v", err)
 	}
@@ -133,8 +136,87 @@ func TestFileTranslator_TranslateBlocks(t *testing.T) {
 	_ = source
 
 	// Just verify the method doesn't panic with a valid file
-	_, err = ft.TranslateBlocks(nil)
+	_, _, err = ft.TranslateBlocks(context.Background(), nil)
 	if err != nil {
 		t.Fatalf("TranslateBlocks failed: %v", err)
 	}
 }
+
+func TestFileTranslator_TranslateBlocks_Aligned(t *testing.T) {
+	var warn bytes.Buffer
+	ft := NewFileTranslator("../../testdata/sample_short.es.md", &warn)
+	source, err := os.ReadFile("../../testdata/sample.fr.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sourceBlocks, _ := parser.Parse(source)
+
+	rows, transBlocks, err := ft.TranslateBlocks(context.Background(), sourceBlocks)
+	if err != nil {
+		t.Fatalf("TranslateBlocks failed: %v", err)
+	}
+	if len(rows) != len(sourceBlocks) || len(transBlocks) != len(rows) {
+		t.Fatalf("expected %d rows, got %d and %d", len(sourceBlocks), len(rows), len(transBlocks))
+	}
+	// The short translation covers the first four blocks.
+	if transBlocks[2].Text != "Las estaciones" || transBlocks[3].Kind != parser.BlockParagraph {
+		t.Errorf("unexpected rows %q, %q", transBlocks[2].Text, transBlocks[3].Text)
+	}
+	if transBlocks[4].HTML != "" {
+		t.Errorf("expected a gap after the translated blocks, got %q", transBlocks[4].HTML)
+	}
+	if !strings.Contains(warn.String(), "Warning: alignment: source block 4 (Paragraph, line 9) has no counterpart in the translation") {
+		t.Errorf("expected the gaps to be reported, got:\n%s", warn.String())
+	}
+}
+
+func TestFileTranslator_TranslateBlocks_ExtraBlock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.es.md")
+	if err := os.WriteFile(path, []byte("# Título\n\nUn párrafo añadido.\n\nEl texto del documento.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sourceBlocks, _ := parser.Parse([]byte("# Titre\n\nLe texte du document.\n"))
+
+	var warn bytes.Buffer
+	rows, transBlocks, err := NewFileTranslator(path, &warn).TranslateBlocks(context.Background(), sourceBlocks)
+	if err != nil {
+		t.Fatalf("TranslateBlocks failed: %v", err)
+	}
+	if len(rows) != 3 || rows[1].HTML != "" || rows[2].Text != "Le texte du document." {
+		t.Errorf("expected a source gap in the second row, got %+v", rows)
+	}
+	if transBlocks[1].Text != "Un párrafo añadido." || transBlocks[2].Text != "El texto del documento." {
+		t.Errorf("unexpected translated rows %+v", transBlocks)
+	}
+	if !strings.Contains(warn.String(), "translation block 1 (Paragraph, line 3) has no counterpart in the source") {
+		t.Errorf("expected the extra block to be reported, got:\n%s", warn.String())
+	}
+}
+
+func TestFileTranslator_Cancelled(t *testing.T) {
+	ft := NewFileTranslator("../../testdata/sample.es.md", os.Stderr)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := ft.Translate(ctx, []string{"Bonjour"}, "fr", "es"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Translate should honour cancellation, got %v", err)
+	}
+	if _, _, err := ft.TranslateBlocks(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("TranslateBlocks should honour cancellation, got %v", err)
+	}
+}
+
+func TestGoogleTranslator_Cancelled(t *testing.T) {
+	// A cancelled run must return before any request is sent.
+	g := NewGoogleTranslator(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	got, err := g.Translate(ctx, []string{"Bonjour", "le monde"}, "fr", "es")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancellation error, got %v", err)
+	}
+	if len(got) != 2 || got[0] != "" || got[1] != "" {
+		t.Errorf("no block should be translated, got %q", got)
+	}
+}
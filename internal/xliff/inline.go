@@ -0,0 +1,131 @@
+package xliff
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"bilingual_pdf/internal/mask"
+	"bilingual_pdf/internal/placeholder"
+)
+
+var (
+	// maskTokenPattern matches the tokens of mask.Markdown.
+	maskTokenPattern = regexp.MustCompile(`\{\{M(\d+)\}\}`)
+
+	// linePrefixPattern matches list and blockquote markers at the start of a line.
+	linePrefixPattern = regexp.MustCompile(`^[ \t]*(?:(?:[-*+]|\d{1,9}[.)])[ \t]+|>[ \t]?)+`)
+)
+
+// piece is a run of inline content: text, or markup kept as a placeholder.
+type piece struct {
+	text   string
+	markup bool
+}
+
+// splitMarkup splits a markdown fragment into text and markup: code spans,
+// link destinations, images, URLs and HTML tags (as protected by the mask
+// package), emphasis delimiters, link brackets, and list and blockquote
+// markers. Adjacent markup is merged, and joining the pieces gives back the
+// fragment.
+func splitMarkup(md string) []piece {
+	set := placeholder.NewSet(mask.Prefix)
+	masked := mask.Markdown(md, set)
+
+	var pieces []piece
+	add := func(s string, markup bool) {
+		if s == "" {
+			return
+		}
+		if n := len(pieces); n > 0 && pieces[n-1].markup == markup {
+			pieces[n-1].text += s
+			return
+		}
+		pieces = append(pieces, piece{text: s, markup: markup})
+	}
+
+	lines := strings.SplitAfter(masked, "\n")
+	for _, line := range lines {
+		prefix := linePrefixPattern.FindString(line)
+		add(prefix, true)
+		line = line[len(prefix):]
+
+		for len(line) > 0 {
+			if value, n, ok := maskToken(line, set); ok {
+				add(value, true)
+				line = line[n:]
+				continue
+			}
+			n := delimiter(line, pieces)
+			if n > 0 {
+				add(line[:n], true)
+				line = line[n:]
+				continue
+			}
+			n = 1
+			if line[0] == '\\' && len(line) > 1 {
+				// An escaped character is text, not a delimiter.
+				_, size := utf8.DecodeRuneInString(line[1:])
+				n = 1 + size
+			}
+			add(line[:n], false)
+			line = line[n:]
+		}
+	}
+	return pieces
+}
+
+// maskToken reports whether s starts with a token of set, and returns its
+// value and length.
+func maskToken(s string, set *placeholder.Set) (string, int, bool) {
+	if !strings.HasPrefix(s, "{{M") {
+		return "", 0, false
+	}
+	m := maskTokenPattern.FindStringSubmatchIndex(s)
+	if m == nil || m[0] != 0 {
+		return "", 0, false
+	}
+	i, err := strconv.Atoi(s[m[2]:m[3]])
+	if err != nil || i >= set.Len() {
+		return "", 0, false
+	}
+	return set.Value(i), m[1], true
+}
+
+// delimiter returns the length of the emphasis delimiter run or link
+// bracket at the start of s, or 0. Underscores inside a word are text.
+func delimiter(s string, before []piece) int {
+	switch s[0] {
+	case '[', ']':
+		return 1
+	case '*':
+		return len(s) - len(strings.TrimLeft(s, "*"))
+	case '~':
+		if strings.HasPrefix(s, "~~") {
+			return 2
+		}
+	case '_':
+		n := len(s) - len(strings.TrimLeft(s, "_"))
+		next, _ := utf8.DecodeRuneInString(s[n:])
+		if isWord(lastRune(before)) && isWord(next) {
+			return 0
+		}
+		return n
+	}
+	return 0
+}
+
+// lastRune returns the last rune of the text so far, or utf8.RuneError.
+func lastRune(pieces []piece) rune {
+	if len(pieces) == 0 {
+		return utf8.RuneError
+	}
+	r, _ := utf8.DecodeLastRuneInString(pieces[len(pieces)-1].text)
+	return r
+}
+
+func isWord(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,58 @@
+package xliff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitMarkup(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string // markup pieces in brackets
+	}{
+		{
+			name:  "emphasis and link",
+			input: "Read **the [guide](https://example.com)** now.",
+			want:  "Read {**}the {[}guide{](https://example.com)**} now.",
+		},
+		{
+			name:  "code span and list markers",
+			input: "- Run `make`\n- Then *rest*",
+			want:  "{- }Run {`make`}\n{- }Then {*}rest{*}",
+		},
+		{
+			name:  "underscores inside words are text",
+			input: "Set snake_case to _on_.",
+			want:  "Set snake_case to {_}on{_}.",
+		},
+		{
+			name:  "escaped delimiter is text",
+			input: `Price: 5\* per item`,
+			want:  `Price: 5\* per item`,
+		},
+		{
+			name:  "html tags",
+			input: "<p>Hello <strong>world</strong></p>",
+			want:  "{<p>}Hello {<strong>}world{</strong></p>}",
+		},
+	}
+	for _, tt := range tests {
+		pieces := splitMarkup(tt.input)
+		var got, joined strings.Builder
+		for _, p := range pieces {
+			joined.WriteString(p.text)
+			if p.markup {
+				got.WriteString("{" + p.text + "}")
+			} else {
+				got.WriteString(p.text)
+			}
+		}
+		if got.String() != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got.String(), tt.want)
+		}
+		if joined.String() != tt.input {
+			t.Errorf("%s: pieces join to %q, want the input", tt.name, joined.String())
+		}
+	}
+}
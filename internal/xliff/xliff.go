@@ -0,0 +1,387 @@
+// Package xliff exchanges documents with CAT tools as XLIFF 2.0. Every
+// block of the document is a unit that carries its kind and level as
+// metadata, and inline markdown syntax is replaced by placeholders that
+// translators can move but not alter.
+package xliff
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"bilingual_pdf/internal/xmltext"
+)
+
+// Namespaces of the XLIFF 2.0 core and of its metadata module.
+const (
+	Namespace         = "urn:oasis:names:tc:xliff:document:2.0"
+	MetadataNamespace = "urn:oasis:names:tc:xliff:metadata:2.0"
+)
+
+// metaCategory is the category of the metadata written for each unit.
+const metaCategory = "bilingual_pdf"
+
+// Unit is one block of a document.
+type Unit struct {
+	ID        string // unique within the document, e.g. "b3"
+	Kind      string // block kind, e.g. "Paragraph"
+	Level     int    // heading level, 0 for other blocks
+	Translate bool   // false for blocks kept as they are, such as code blocks
+	Source    string // markdown source
+	Target    string // markdown translation, empty if the unit is not translated
+
+	// Lost lists the markup of the source whose placeholder is missing
+	// from the target. It is set by Read.
+	Lost []string
+}
+
+// Document is a set of units for a language pair.
+type Document struct {
+	SourceLang string
+	TargetLang string
+	Original   string // name of the source file
+	Units      []Unit
+}
+
+// Write encodes the document as XLIFF 2.0.
+func (d *Document) Write(w io.Writer) error {
+	var buf strings.Builder
+	buf.WriteString(xml.Header)
+	fmt.Fprintf(&buf, `<xliff xmlns="%s" xmlns:mda="%s" version="2.0" srcLang="%s"`,
+		Namespace, MetadataNamespace, xmltext.Escape(d.SourceLang))
+	if d.TargetLang != "" {
+		fmt.Fprintf(&buf, ` trgLang="%s"`, xmltext.Escape(d.TargetLang))
+	}
+	buf.WriteString(">\n")
+	fmt.Fprintf(&buf, "  <file id=\"f1\" original=\"%s\">\n", xmltext.Escape(d.Original))
+	for _, u := range d.Units {
+		writeUnit(&buf, u)
+	}
+	buf.WriteString("  </file>\n</xliff>\n")
+
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+func writeUnit(buf *strings.Builder, u Unit) {
+	fmt.Fprintf(buf, "    <unit id=\"%s\"", xmltext.Escape(u.ID))
+	if !u.Translate {
+		buf.WriteString(` translate="no"`)
+	}
+	buf.WriteString(">\n")
+
+	buf.WriteString("      <mda:metadata>\n")
+	fmt.Fprintf(buf, "        <mda:metaGroup category=\"%s\">\n", metaCategory)
+	fmt.Fprintf(buf, "          <mda:meta type=\"kind\">%s</mda:meta>\n", xmltext.Escape(u.Kind))
+	if u.Level > 0 {
+		fmt.Fprintf(buf, "          <mda:meta type=\"level\">%d</mda:meta>\n", u.Level)
+	}
+	buf.WriteString("        </mda:metaGroup>\n      </mda:metadata>\n")
+
+	source := []piece{{text: u.Source}}
+	var target []piece
+	if u.Translate {
+		source = splitMarkup(u.Source)
+	}
+	if u.Target != "" {
+		target = []piece{{text: u.Target}}
+		if u.Translate {
+			target = splitMarkup(u.Target)
+		}
+	}
+
+	// Every distinct markup value is stored once; placeholders of the
+	// target reuse the id of the same occurrence in the source.
+	data := &originalData{ids: map[string]string{}}
+	sourceXML := data.inline(source, nil)
+	targetXML := data.inline(target, source)
+
+	if len(data.values) > 0 {
+		buf.WriteString("      <originalData>\n")
+		for i, v := range data.values {
+			fmt.Fprintf(buf, "        <data id=\"d%d\">%s</data>\n", i+1, xmltext.Escape(v))
+		}
+		buf.WriteString("      </originalData>\n")
+	}
+	buf.WriteString("      <segment>\n")
+	fmt.Fprintf(buf, "        <source>%s</source>\n", sourceXML)
+	if u.Target != "" {
+		fmt.Fprintf(buf, "        <target>%s</target>\n", targetXML)
+	}
+	buf.WriteString("      </segment>\n    </unit>\n")
+}
+
+// originalData collects the markup of a unit.
+type originalData struct {
+	values []string
+	ids    map[string]string // value to data id
+}
+
+// inline encodes pieces as XLIFF inline content. Placeholders are numbered
+// by occurrence; when source is given, the k-th occurrence of a markup value
+// gets the id of its k-th occurrence in the source.
+func (d *originalData) inline(pieces, source []piece) string {
+	var buf strings.Builder
+	seen := map[string]int{}
+	id, next := 0, countMarkup(source)+1
+	for _, p := range pieces {
+		if !p.markup {
+			buf.WriteString(xmltext.Escape(p.text))
+			continue
+		}
+		ref, ok := d.ids[p.text]
+		if !ok {
+			d.values = append(d.values, p.text)
+			ref = "d" + strconv.Itoa(len(d.values))
+			d.ids[p.text] = ref
+		}
+
+		if source == nil {
+			id++
+		} else {
+			seen[p.text]++
+			if id = occurrence(source, p.text, seen[p.text]); id == 0 {
+				id = next
+				next++
+			}
+		}
+		fmt.Fprintf(&buf, `<ph id="%d" dataRef="%s"/>`, id, ref)
+	}
+	return buf.String()
+}
+
+// countMarkup returns the number of markup pieces.
+func countMarkup(pieces []piece) int {
+	n := 0
+	for _, p := range pieces {
+		if p.markup {
+			n++
+		}
+	}
+	return n
+}
+
+// occurrence returns the placeholder id of the n-th occurrence of markup
+// value v in source, or 0.
+func occurrence(source []piece, v string, n int) int {
+	id := 0
+	for _, p := range source {
+		if !p.markup {
+			continue
+		}
+		id++
+		if p.text == v {
+			if n--; n == 0 {
+				return id
+			}
+		}
+	}
+	return 0
+}
+
+// Read decodes an XLIFF 2.0 document. Units may be nested in groups and
+// split into several segments; their targets are joined, placeholders being
+// replaced by the markup they stand for.
+func Read(r io.Reader) (*Document, error) {
+	dec := xml.NewDecoder(r)
+	doc := &Document{}
+	root := false
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing XLIFF: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "xliff":
+			root = true
+			if v := attr(start, "version"); !strings.HasPrefix(v, "2.") {
+				return nil, fmt.Errorf("unsupported XLIFF version %q: expected 2.0", v)
+			}
+			doc.SourceLang = attr(start, "srcLang")
+			doc.TargetLang = attr(start, "trgLang")
+		case "file":
+			if doc.Original == "" {
+				doc.Original = attr(start, "original")
+			}
+		case "unit":
+			var xu xmlUnit
+			if err := dec.DecodeElement(&xu, &start); err != nil {
+				return nil, fmt.Errorf("parsing XLIFF unit: %w", err)
+			}
+			doc.Units = append(doc.Units, xu.unit())
+		}
+	}
+	if !root {
+		return nil, fmt.Errorf("parsing XLIFF: no <xliff> element")
+	}
+	return doc, nil
+}
+
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+type xmlUnit struct {
+	ID        string `xml:"id,attr"`
+	Translate string `xml:"translate,attr"`
+	Meta      []struct {
+		Type  string `xml:"type,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"metadata>metaGroup>meta"`
+	Data []struct {
+		ID    string `xml:"id,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"originalData>data"`
+	Parts []xmlPart `xml:",any"`
+}
+
+// xmlPart is a segment or an ignorable.
+type xmlPart struct {
+	XMLName xml.Name
+	Source  content  `xml:"source"`
+	Target  *content `xml:"target"`
+}
+
+func (xu xmlUnit) unit() Unit {
+	u := Unit{ID: xu.ID, Translate: xu.Translate != "no"}
+	for _, m := range xu.Meta {
+		switch m.Type {
+		case "kind":
+			u.Kind = m.Value
+		case "level":
+			u.Level, _ = strconv.Atoi(m.Value)
+		}
+	}
+	data := map[string]string{}
+	for _, d := range xu.Data {
+		data[d.ID] = d.Value
+	}
+
+	var source, target strings.Builder
+	translated := false
+	used := map[string]bool{}
+	var refs []string
+	for _, p := range xu.Parts {
+		if p.XMLName.Local != "segment" && p.XMLName.Local != "ignorable" {
+			continue
+		}
+		source.WriteString(p.Source.markdown(data, nil))
+		refs = append(refs, p.Source.refs()...)
+		if p.Target != nil {
+			translated = true
+			target.WriteString(p.Target.markdown(data, used))
+		} else {
+			target.WriteString(p.Source.markdown(data, used))
+		}
+	}
+	u.Source = source.String()
+	if translated {
+		u.Target = target.String()
+		for _, ref := range refs {
+			if !used[ref] {
+				u.Lost = append(u.Lost, data[ref])
+				used[ref] = true // reported once
+			}
+		}
+	}
+	return u
+}
+
+// content is inline XLIFF content: text mixed with placeholders and
+// annotations.
+type content struct {
+	nodes []node
+}
+
+// node is a run of text, or a reference to original data.
+type node struct {
+	text string
+	ref  string
+}
+
+func (c *content) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			c.nodes = append(c.nodes, node{text: string(t)})
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "ph", "sc", "ec":
+				c.nodes = append(c.nodes, node{ref: attr(t, "dataRef")})
+			case "pc":
+				c.nodes = append(c.nodes, node{ref: attr(t, "dataRefStart")})
+				var inner content
+				if err := inner.UnmarshalXML(dec, t); err != nil {
+					return err
+				}
+				c.nodes = append(c.nodes, inner.nodes...)
+				c.nodes = append(c.nodes, node{ref: attr(t, "dataRefEnd")})
+				continue
+			case "cp":
+				if r, err := strconv.ParseUint(attr(t, "hex"), 16, 32); err == nil {
+					c.nodes = append(c.nodes, node{text: string(rune(r))})
+				}
+			default:
+				// Annotations such as <mrk> keep their text.
+				var inner content
+				if err := inner.UnmarshalXML(dec, t); err != nil {
+					return err
+				}
+				c.nodes = append(c.nodes, inner.nodes...)
+				continue
+			}
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// markdown returns the content with placeholders replaced by their data,
+// recording the references used.
+func (c content) markdown(data map[string]string, used map[string]bool) string {
+	var buf strings.Builder
+	for _, n := range c.nodes {
+		if n.ref == "" {
+			buf.WriteString(n.text)
+			continue
+		}
+		buf.WriteString(data[n.ref])
+		if used != nil {
+			used[n.ref] = true
+		}
+	}
+	return buf.String()
+}
+
+// refs returns the data references of the content.
+func (c content) refs() []string {
+	var refs []string
+	for _, n := range c.nodes {
+		if n.ref != "" {
+			refs = append(refs, n.ref)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,158 @@
+package xliff
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func sampleDocument() *Document {
+	return &Document{
+		SourceLang: "fr",
+		TargetLang: "es",
+		Original:   "doc.fr.md",
+		Units: []Unit{
+			{ID: "b0", Kind: "Heading", Level: 1, Translate: true, Source: "Bonjour & bienvenue"},
+			{ID: "b1", Kind: "Paragraph", Translate: true, Source: "Lisez **le [guide](https://example.com)** et lancez `make`."},
+			{ID: "b2", Kind: "CodeBlock", Translate: false, Source: "print(\"*a*\")\n"},
+		},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleDocument().Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" xmlns:mda="urn:oasis:names:tc:xliff:metadata:2.0" version="2.0" srcLang="fr" trgLang="es">`,
+		`<file id="f1" original="doc.fr.md">`,
+		`<mda:meta type="kind">Heading</mda:meta>`,
+		`<mda:meta type="level">1</mda:meta>`,
+		`<source>Bonjour &amp; bienvenue</source>`,
+		`<data id="d1">**</data>`,
+		`<source>Lisez <ph id="1" dataRef="d1"/>le <ph id="2" dataRef="d2"/>guide<ph id="3" dataRef="d3"/> et lancez <ph id="4" dataRef="d4"/>.</source>`,
+		`<unit id="b2" translate="no">`,
+		`<source>print(&#34;*a*&#34;)` + "\n</source>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %s\n%s", want, out)
+		}
+	}
+}
+
+func TestReadRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleDocument().Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// A translator fills in the targets, moving the placeholders.
+	filled := strings.Replace(buf.String(),
+		`<source>Lisez <ph id="1" dataRef="d1"/>le <ph id="2" dataRef="d2"/>guide<ph id="3" dataRef="d3"/> et lancez <ph id="4" dataRef="d4"/>.</source>`,
+		`<source>Lisez <ph id="1" dataRef="d1"/>le <ph id="2" dataRef="d2"/>guide<ph id="3" dataRef="d3"/> et lancez <ph id="4" dataRef="d4"/>.</source>`+
+			`<target>Ejecute <ph id="4" dataRef="d4"/> y lea <ph id="1" dataRef="d1"/>la <ph id="2" dataRef="d2"/>guía<ph id="3" dataRef="d3"/>.</target>`, 1)
+	filled = strings.Replace(filled, "<source>Bonjour &amp; bienvenue</source>",
+		"<source>Bonjour &amp; bienvenue</source><target>Hola y bienvenido</target>", 1)
+
+	doc, err := Read(strings.NewReader(filled))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if doc.SourceLang != "fr" || doc.TargetLang != "es" || doc.Original != "doc.fr.md" || len(doc.Units) != 3 {
+		t.Fatalf("unexpected document %+v", doc)
+	}
+
+	want := sampleDocument().Units
+	for i, u := range doc.Units {
+		if u.ID != want[i].ID || u.Kind != want[i].Kind || u.Level != want[i].Level ||
+			u.Translate != want[i].Translate || u.Source != want[i].Source {
+			t.Errorf("unit %d: got %+v, want %+v", i, u, want[i])
+		}
+	}
+	if got := doc.Units[0].Target; got != "Hola y bienvenido" {
+		t.Errorf("unexpected heading target %q", got)
+	}
+	if got := doc.Units[1].Target; got != "Ejecute `make` y lea **la [guía](https://example.com)**." {
+		t.Errorf("unexpected paragraph target %q", got)
+	}
+	if doc.Units[2].Target != "" {
+		t.Errorf("untranslated unit should have no target, got %q", doc.Units[2].Target)
+	}
+}
+
+func TestWriteTarget(t *testing.T) {
+	doc := &Document{SourceLang: "fr", TargetLang: "es", Units: []Unit{
+		{ID: "b0", Kind: "Paragraph", Translate: true, Source: "Un *mot* et un *autre*", Target: "Una *palabra* y *otra*"},
+	}}
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	target := regexp.MustCompile(`<target>.*</target>`).FindString(buf.String())
+	want := `<target>Una <ph id="1" dataRef="d1"/>palabra<ph id="2" dataRef="d1"/> y <ph id="3" dataRef="d1"/>otra<ph id="4" dataRef="d1"/></target>`
+	if target != want {
+		t.Errorf("got %s, want %s", target, want)
+	}
+
+	back, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Units[0].Target != doc.Units[0].Target {
+		t.Errorf("target did not round-trip: %q", back.Units[0].Target)
+	}
+}
+
+func TestRead_ToolOutput(t *testing.T) {
+	// Groups, several segments, paired codes and annotations, as CAT tools
+	// may write them.
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.1" srcLang="fr" trgLang="de">
+  <file id="f1" original="a.md">
+    <group id="g1">
+      <unit id="b0">
+        <originalData>
+          <data id="d1">**</data>
+          <data id="d2">` + "`x`" + `</data>
+        </originalData>
+        <segment><source>Un <pc id="1" dataRefStart="d1" dataRefEnd="d1">mot</pc>.</source><target>Ein <pc id="1" dataRefStart="d1" dataRefEnd="d1"><mrk id="m1" translate="yes">Wort</mrk></pc>.</target></segment>
+        <ignorable><source> </source></ignorable>
+        <segment><source>Voir <ph id="2" dataRef="d2"/>.</source><target>Siehe.</target></segment>
+      </unit>
+    </group>
+  </file>
+</xliff>`
+	doc, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(doc.Units) != 1 {
+		t.Fatalf("expected 1 unit, got %d", len(doc.Units))
+	}
+	u := doc.Units[0]
+	if u.Source != "Un **mot**. Voir `x`." {
+		t.Errorf("unexpected source %q", u.Source)
+	}
+	if u.Target != "Ein **Wort**. Siehe." {
+		t.Errorf("unexpected target %q", u.Target)
+	}
+	if len(u.Lost) != 1 || u.Lost[0] != "`x`" {
+		t.Errorf("expected the code span to be reported lost, got %q", u.Lost)
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	for _, input := range []string{
+		"not xml at all <",
+		`<xliff version="1.2"><file/></xliff>`,
+		`<other/>`,
+	} {
+		if _, err := Read(strings.NewReader(input)); err == nil {
+			t.Errorf("expected an error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+// Package xmltext escapes text for the XML files the app writes, TMX and
+// XLIFF.
+package xmltext
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// Escape escapes text for XML content and attributes, keeping line breaks.
+func Escape(s string) string {
+	var buf strings.Builder
+	_ = xml.EscapeText(&buf, []byte(s))
+	return strings.ReplaceAll(buf.String(), "&#xA;", "\n")
+}
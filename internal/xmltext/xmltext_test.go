@@ -0,0 +1,10 @@
+package xmltext
+
+import "testing"
+
+func TestEscape(t *testing.T) {
+	got := Escape("a < b & \"c\"\nd")
+	if want := "a &lt; b &amp; &#34;c&#34;\nd"; got != want {
+		t.Errorf("Escape = %q, want %q", got, want)
+	}
+}